@@ -11,5 +11,7 @@ require (
 	github.com/sirupsen/logrus v1.6.0
 	github.com/stretchr/testify v1.6.1
 	go.ligato.io/vpp-agent/v3 v3.1.0
+	golang.org/x/sys v0.0.0-20200916084744-dbad9cb7cb7a
 	google.golang.org/grpc v1.32.0
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -0,0 +1,88 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	aclHeaderRegexp = regexp.MustCompile(`^acl-index \d+ count \d+ tag \{(.*)\}$`)
+	aclRuleRegexp   = regexp.MustCompile(`^\s+(\d+): .*\bhitcount pkts (\d+)`)
+)
+
+// VppctlCounters - CountersFunc reading the hits of the acl rules from 'vppctl show acl-plugin acl' of the local VPP.
+//                  VPP counts them only while the acl-plugin interface counters are enabled, the acl without the
+//                  counts is an error.
+func VppctlCounters(ctx context.Context, aclName string) ([]uint64, error) {
+	out, err := exec.CommandContext(ctx, "vppctl", "show", "acl-plugin", "acl").Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run vppctl show acl-plugin acl")
+	}
+	return parseCounters(out, aclName)
+}
+
+// parseCounters - returns the hits per rule of the acl tagged with the name in the output of
+// 'show acl-plugin acl'
+func parseCounters(out []byte, aclName string) ([]uint64, error) {
+	var rv []uint64
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := aclHeaderRegexp.FindStringSubmatch(line); match != nil {
+			if found {
+				break
+			}
+			found = match[1] == aclName
+			continue
+		}
+		if !found {
+			continue
+		}
+		match := aclRuleRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(match[1])
+		if index != len(rv) {
+			return nil, errors.Errorf("unexpected rule %d of acl %s, expected %d", index, aclName, len(rv))
+		}
+		hits, err := strconv.ParseUint(match[2], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid hitcount of rule %d of acl %s", index, aclName)
+		}
+		rv = append(rv, hits)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !found {
+		return nil, errors.Errorf("acl %s is not found", aclName)
+	}
+	if rv == nil {
+		return nil, errors.Errorf("acl %s has no hitcounts, the acl-plugin counters are disabled", aclName)
+	}
+	return rv, nil
+}
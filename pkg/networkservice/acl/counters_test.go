@@ -0,0 +1,79 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/acl"
+)
+
+const showACLOutput = `acl-index 0 count 2 tag {other}
+          0: ipv4 permit src 0.0.0.0/0 dst 10.0.0.0/8 proto 0 sport 0-65535 dport 0-65535 hitcount pkts 9 bytes 900
+          1: ipv4 deny src 0.0.0.0/0 dst 0.0.0.0/0 proto 0 sport 0-65535 dport 0-65535 hitcount pkts 8 bytes 800
+  applied inbound on sw_if_index: 1
+acl-index 1 count 3 tag {firewall}
+          0: ipv4 permit src 0.0.0.0/0 dst 10.61.0.0/16 proto 0 sport 0-65535 dport 0-65535 hitcount pkts 5 bytes 500
+          1: ipv4 deny src 0.0.0.0/0 dst 0.0.0.0/0 proto 0 sport 0-65535 dport 0-65535 hitcount pkts 1 bytes 100
+          2: ipv6 deny src ::/0 dst ::/0 proto 0 sport 0-65535 dport 0-65535 hitcount pkts 2 bytes 200
+  applied inbound on sw_if_index: 2
+acl-index 2 count 1 tag {nocounters}
+          0: ipv4 deny src 0.0.0.0/0 dst 0.0.0.0/0 proto 0 sport 0-65535 dport 0-65535
+`
+
+// fakeVppctl - puts vppctl printing the output into PATH, returns the func restoring PATH
+func fakeVppctl(t *testing.T, output string) func() {
+	dir, err := ioutil.TempDir("", "vppctl")
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "output"), []byte(output), 0600))
+	script := "#!/bin/sh\ncat " + filepath.Join(dir, "output") + "\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "vppctl"), []byte(script), 0700))
+	path := os.Getenv("PATH")
+	require.NoError(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+path))
+	return func() {
+		_ = os.Setenv("PATH", path)
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestVppctlCounters(t *testing.T) {
+	defer fakeVppctl(t, showACLOutput)()
+
+	hits, err := acl.VppctlCounters(context.Background(), "firewall")
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{5, 1, 2}, hits)
+
+	hits, err = acl.VppctlCounters(context.Background(), "other")
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{9, 8}, hits)
+}
+
+func TestVppctlCountersErrors(t *testing.T) {
+	defer fakeVppctl(t, showACLOutput)()
+
+	_, err := acl.VppctlCounters(context.Background(), "missing")
+	assert.Error(t, err)
+	_, err = acl.VppctlCounters(context.Background(), "nocounters")
+	assert.Error(t, err)
+}
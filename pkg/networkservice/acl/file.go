@@ -0,0 +1,248 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+	"google.golang.org/grpc"
+	"gopkg.in/yaml.v3"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	ingressKey = "ingress"
+	egressKey  = "egress"
+
+	defaultReloadInterval = 5 * time.Second
+	vppagentTimeout       = 10 * time.Second
+)
+
+// NewServerFromFile - creates a NetworkServiceServer like NewServer with the ingress and egress rules from the file,
+//                     see ParseRules for the format. The file is checked for changes until ctx is done: a valid new
+//                     rule set replaces the acls of all the live connections in a single vppagent transaction, an
+//                     invalid one is logged and the old set is kept.
+func NewServerFromFile(ctx context.Context, filename string, vppagentCC grpc.ClientConnInterface, opts ...Option) (networkservice.NetworkServiceServer, error) {
+	data, err := ioutil.ReadFile(filename) // #nosec
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read acl rules file %s", filename)
+	}
+	static, err := ParseRules(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid acl rules file %s", filename)
+	}
+	a := newACL(static.Ingress, append(opts, WithEgressRules(static.Egress))...)
+	f := &rulesFile{
+		filename:       filename,
+		acl:            a,
+		vppagentClient: configurator.NewConfiguratorServiceClient(vppagentCC),
+		reloadInterval: defaultReloadInterval,
+	}
+	if a.options.reloadInterval > 0 {
+		f.reloadInterval = a.options.reloadInterval
+	}
+	go f.watch(ctx, data)
+	return a, nil
+}
+
+type rulesFile struct {
+	filename       string
+	acl            *acl
+	vppagentClient configurator.ConfiguratorServiceClient
+	reloadInterval time.Duration
+}
+
+// watch - polls the file, polling also catches the file replaced by a symlink swap like a mounted ConfigMap
+func (f *rulesFile) watch(ctx context.Context, data []byte) {
+	ticker := time.NewTicker(f.reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		newData, err := ioutil.ReadFile(f.filename)
+		if err != nil {
+			log.Entry(ctx).Warnf("failed to read acl rules file %s: %v", f.filename, err)
+			continue
+		}
+		if bytes.Equal(newData, data) {
+			continue
+		}
+		static, err := ParseRules(newData)
+		if err != nil {
+			log.Entry(ctx).Errorf("invalid acl rules file %s, keeping the old rules: %v", f.filename, err)
+			data = newData
+			continue
+		}
+		// A failed reload is retried on the next tick
+		if reloadErr := f.reload(ctx, static); reloadErr != nil {
+			log.Entry(ctx).Errorf("failed to reload acl rules file %s: %v", f.filename, reloadErr)
+			continue
+		}
+		data = newData
+	}
+}
+
+// reload - attaches the interfaces of the live connections to the acls of the new rules and deletes the acls left
+// with no interfaces in a single commit. The policies of the connections are looked up first, then the new state is
+// built in a copy of the acls and replaces the current one only if the commit succeeds, the connections and the acls
+// stay locked until then, so no Request can change them in between. The connections requested meanwhile pick up the
+// new rules on their refresh.
+func (f *rulesFile) reload(ctx context.Context, static *Policy) error {
+	policies := f.acl.lookupAll(ctx, static)
+	ctx = vppagent.WithConfig(ctx)
+	var reloaded *sharedACLs
+	vppagent.OnCommit(ctx, func(configs *vppagent.CommitConfigs) {
+		f.acl.mu.Lock()
+		globalACLs.mu.Lock()
+
+		reloaded = globalACLs.clone()
+		changed := f.acl.reattach(ctx, reloaded, policies)
+		reloaded.put(configs, append(changed, reloaded.prune()...))
+	})
+	return vppagent.Commit(ctx, false, func(update, remove *configurator.Config) error {
+		defer f.acl.mu.Unlock()
+		defer globalACLs.mu.Unlock()
+
+		// The Requests wait for the acls meanwhile
+		sendCtx, cancel := context.WithTimeout(ctx, vppagentTimeout)
+		defer cancel()
+		if len(update.GetVppConfig().GetAcls()) > 0 {
+			if _, err := f.vppagentClient.Update(sendCtx, &configurator.UpdateRequest{Update: update}); err != nil {
+				return errors.Wrapf(err, "error sending config to vppagent %v: ", update)
+			}
+		}
+		if remove != nil {
+			if _, err := f.vppagentClient.Delete(sendCtx, &configurator.DeleteRequest{Delete: remove}); err != nil {
+				return errors.Wrapf(err, "error deleting config from vppagent %v: ", remove)
+			}
+		}
+		globalACLs.acls = reloaded.acls
+		f.acl.static = static
+		return nil
+	})
+}
+
+// ParseRules - parses the YAML rules, either in the MapToRules format applied as ingress rules:
+//              allow-http: action=permit,tcplowport=80,tcpupport=80
+//              or with the ingress and egress rules in the MapToRules format or as lists of the rule keys:
+//              ingress:
+//                - {action: permit, tcplowport: 80, tcpupport: 80}
+//              egress:
+//                allow-dns: action=permit,udplowport=53,udpupport=53
+func ParseRules(data []byte) (*Policy, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the rules")
+	}
+	ingress, hasIngress := doc[ingressKey]
+	egress, hasEgress := doc[egressKey]
+	if !hasIngress && !hasEgress {
+		rules, err := parseRuleList(ingressKey, doc)
+		if err != nil {
+			return nil, err
+		}
+		return &Policy{Ingress: rules}, nil
+	}
+	for key := range doc {
+		if key != ingressKey && key != egressKey {
+			return nil, errors.Errorf("unknown key '%s' next to '%s' and '%s'", key, ingressKey, egressKey)
+		}
+	}
+	rv := &Policy{}
+	var err error
+	if rv.Ingress, err = parseRuleList(ingressKey, ingress); err != nil {
+		return nil, err
+	}
+	if rv.Egress, err = parseRuleList(egressKey, egress); err != nil {
+		return nil, err
+	}
+	if err = validatePolicy(rv); err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
+
+// parseRuleList - parses the rules in the MapToRules format or a list of the rule key maps
+func parseRuleList(name string, value interface{}) ([]*vppacl.ACL_Rule, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		rules := make(map[string]string, len(v))
+		for key, rule := range v {
+			s, ok := rule.(string)
+			if !ok {
+				return nil, errors.Errorf("parsing rule %s failed with: rule should be a string", key)
+			}
+			rules[key] = s
+		}
+		return MapToRules(rules)
+	case []interface{}:
+		var rv []*vppacl.ACL_Rule
+		for i, item := range v {
+			key := fmt.Sprintf("%s[%d]", name, i)
+			rule, err := joinRuleKeys(item)
+			if err != nil {
+				return nil, errors.Errorf("parsing rule %s failed with: %v", key, err)
+			}
+			rules, err := MapToRules(map[string]string{key: rule})
+			if err != nil {
+				return nil, err
+			}
+			rv = append(rv, rules...)
+		}
+		return rv, nil
+	}
+	return nil, errors.Errorf("'%s' should be a map or a list of rules", name)
+}
+
+// joinRuleKeys - joins the map of the rule keys into the MapToRules format
+func joinRuleKeys(item interface{}) (string, error) {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return "", errors.New("rule should be a map")
+	}
+	pairs := make([]string, 0, len(m))
+	for key, value := range m {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			return "", errors.Errorf("'%s' should be a scalar", key)
+		}
+		s := fmt.Sprint(value)
+		if strings.ContainsAny(s, ",=") {
+			return "", errors.Errorf("'%s' has invalid value '%s'", key, s)
+		}
+		pairs = append(pairs, key+"="+s)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ","), nil
+}
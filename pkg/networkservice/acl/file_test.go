@@ -0,0 +1,247 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/acl"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+// vppagentCC - records the configs sent to vppagent, fails them while fail is set
+type vppagentCC struct {
+	grpc.ClientConnInterface
+	updates  []*configurator.Config
+	deletes  []*configurator.Config
+	fail     bool
+	failures int
+	mu       sync.Mutex
+}
+
+func (v *vppagentCC) Invoke(_ context.Context, _ string, args, _ interface{}, _ ...grpc.CallOption) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.fail {
+		v.failures++
+		return errors.New("vppagent failure")
+	}
+	switch r := args.(type) {
+	case *configurator.UpdateRequest:
+		v.updates = append(v.updates, r.GetUpdate())
+	case *configurator.DeleteRequest:
+		v.deletes = append(v.deletes, r.GetDelete())
+	}
+	return nil
+}
+
+func (v *vppagentCC) configs() (updates, deletes []*configurator.Config) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append(updates, v.updates...), append(deletes, v.deletes...)
+}
+
+func (v *vppagentCC) setFail(fail bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fail = fail
+}
+
+func (v *vppagentCC) failureCount() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.failures
+}
+
+func TestParseRules(t *testing.T) {
+	policy, err := acl.ParseRules([]byte(`
+b: action=deny,dstnet=10.70.1.0/24
+a: action=permit,dstnet=10.70.0.0/16
+`))
+	require.NoError(t, err)
+	assert.Equal(t, &acl.Policy{Ingress: []*vppacl.ACL_Rule{
+		rule(vppacl.ACL_Rule_PERMIT, "10.70.0.0/16"),
+		rule(vppacl.ACL_Rule_DENY, "10.70.1.0/24"),
+	}}, policy)
+
+	policy, err = acl.ParseRules([]byte(`
+ingress:
+  - {action: deny, dstnet: 10.70.1.0/24}
+  - action: permit
+    dstnet: 10.70.0.0/16
+egress:
+  deny: action=deny,dstnet=10.70.2.0/24
+`))
+	require.NoError(t, err)
+	assert.Equal(t, &acl.Policy{
+		Ingress: []*vppacl.ACL_Rule{
+			rule(vppacl.ACL_Rule_DENY, "10.70.1.0/24"),
+			rule(vppacl.ACL_Rule_PERMIT, "10.70.0.0/16"),
+		},
+		Egress: []*vppacl.ACL_Rule{
+			rule(vppacl.ACL_Rule_DENY, "10.70.2.0/24"),
+		},
+	}, policy)
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	for data, message := range map[string]string{
+		"a: [action=permit":                                    "failed to parse",
+		"a: action=drop":                                       "rule a",
+		"a: {action: permit}":                                  "rule a",
+		"ingress: []\nother: action=permit":                    "unknown key 'other'",
+		"ingress:\n  - {action: permit, dstnet: [a]}":          "rule ingress[0]",
+		"ingress:\n  - {action: permit}\n  - {tcplowport: 80}": "rule ingress[1]",
+		"egress: action=permit":                                "'egress' should be a map or a list of rules",
+	} {
+		_, err := acl.ParseRules([]byte(data))
+		require.Error(t, err, data)
+		assert.Contains(t, err.Error(), message, data)
+	}
+}
+
+func TestNewServerFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acl")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	filename := filepath.Join(dir, "rules.yaml")
+	writeRules(t, filename, []byte("a: action=deny,dstnet=10.80.0.0/16"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cc := &vppagentCC{}
+	server, err := acl.NewServerFromFile(ctx, filename, cc, acl.WithReloadInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	server = chainWithMemif(server)
+
+	oldRules := []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_DENY, "10.80.0.0/16")}
+	configCtx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(configCtx, request("file", nil))
+	require.NoError(t, err)
+	update, _ := commit(configCtx, t, false)
+	oldACL := findACL(update, oldRules)
+	require.NotNil(t, oldACL)
+
+	// The new rules replace the acl of the live connection
+	newRules := []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_DENY, "10.81.0.0/16")}
+	writeRules(t, filename, []byte("ingress:\n  - {action: deny, dstnet: 10.81.0.0/16}"))
+	require.Eventually(t, func() bool {
+		_, deletes := cc.configs()
+		return len(deletes) == 1
+	}, time.Second, 10*time.Millisecond)
+	updates, deletes := cc.configs()
+	require.Len(t, updates, 1)
+	require.Len(t, updates[0].GetVppConfig().GetAcls(), 1)
+	assert.Equal(t, newRules, updates[0].GetVppConfig().GetAcls()[0].GetRules())
+	assert.Equal(t, []string{"server-file"}, updates[0].GetVppConfig().GetAcls()[0].GetInterfaces().GetIngress())
+	require.Len(t, deletes[0].GetVppConfig().GetAcls(), 1)
+	assert.Equal(t, oldACL.GetName(), deletes[0].GetVppConfig().GetAcls()[0].GetName())
+
+	// The invalid rules are ignored
+	writeRules(t, filename, []byte("a: action=drop"))
+	time.Sleep(50 * time.Millisecond)
+	updates, _ = cc.configs()
+	assert.Len(t, updates, 1)
+	configCtx = vppagent.WithConfig(context.Background())
+	conn, err = server.Request(configCtx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	update, _ = commit(configCtx, t, false)
+	assert.NotNil(t, findACL(update, newRules))
+
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn)
+	require.NoError(t, err)
+}
+
+func TestNewServerFromFileFailedReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acl")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	filename := filepath.Join(dir, "rules.yaml")
+	writeRules(t, filename, []byte("a: action=deny,dstnet=10.82.0.0/16"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cc := &vppagentCC{}
+	server, err := acl.NewServerFromFile(ctx, filename, cc, acl.WithReloadInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	server = chainWithMemif(server)
+
+	oldRules := []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_DENY, "10.82.0.0/16")}
+	configCtx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(configCtx, request("file-failed", nil))
+	require.NoError(t, err)
+	_, _ = commit(configCtx, t, false)
+
+	// The failed reload keeps the old state
+	cc.setFail(true)
+	writeRules(t, filename, []byte("a: action=deny,dstnet=10.83.0.0/16"))
+	require.Eventually(t, func() bool {
+		return cc.failureCount() > 0
+	}, time.Second, 10*time.Millisecond)
+	configCtx = vppagent.WithConfig(context.Background())
+	conn, err = server.Request(configCtx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	update, _ := commit(configCtx, t, false)
+	require.NotNil(t, findACL(update, oldRules))
+	assert.Equal(t, []string{"server-file-failed"}, findACL(update, oldRules).GetInterfaces().GetIngress())
+
+	// The reload is retried
+	cc.setFail(false)
+	require.Eventually(t, func() bool {
+		_, deletes := cc.configs()
+		return len(deletes) == 1
+	}, time.Second, 10*time.Millisecond)
+	updates, _ := cc.configs()
+	require.Len(t, updates, 1)
+	require.Len(t, updates[0].GetVppConfig().GetAcls(), 1)
+	assert.Equal(t, []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_DENY, "10.83.0.0/16")}, updates[0].GetVppConfig().GetAcls()[0].GetRules())
+
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn)
+	require.NoError(t, err)
+}
+
+func TestNewServerFromFileInvalid(t *testing.T) {
+	_, err := acl.NewServerFromFile(context.Background(), "/nonexistent/rules.yaml", &vppagentCC{})
+	require.Error(t, err)
+}
+
+// writeRules - replaces the rules file with a rename, so the reload never reads it half written
+func writeRules(t *testing.T, filename string, data []byte) {
+	tmp := filename + ".tmp"
+	require.NoError(t, ioutil.WriteFile(tmp, data, 0600))
+	require.NoError(t, os.Rename(tmp, filename))
+}
+
+func chainWithMemif(server networkservice.NetworkServiceServer) networkservice.NetworkServiceServer {
+	return chain.NewNetworkServiceServer(memif.NewServer("BaseDir"), server)
+}
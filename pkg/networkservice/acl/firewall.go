@@ -0,0 +1,259 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	// firewallConnSuffix - the firewall holds the shared acls separately from the acl server of the same connection
+	firewallConnSuffix = "/firewall"
+
+	firewallRuleHitsMetric    = "firewall_rule_%d_hits"
+	firewallDefaultDenyMetric = "firewall_default_deny_hits"
+
+	countersTimeout = 5 * time.Second
+)
+
+// FirewallPolicyFunc - returns the rules of the flows allowed for the network service, everything else is denied
+type FirewallPolicyFunc func(ctx context.Context, networkService string) ([]*vppacl.ACL_Rule, error)
+
+// CountersFunc - returns the number of the matches per rule of the acl
+type CountersFunc func(ctx context.Context, aclName string) ([]uint64, error)
+
+// FirewallOption - option for NewFirewallClient
+type FirewallOption func(f *firewallClient)
+
+// WithCountersFunc - sets the func reading the acl counters, for example VppctlCounters, no counters are reported by
+//                    default. The counters are read in the background after the commits of the connection and the
+//                    hits of the allowed rules and of the default deny read last are reported in the metrics of the
+//                    path segment on the next Request. The counters are per acl, so they are the totals of all the
+//                    connections of the network service.
+func WithCountersFunc(counters CountersFunc) FirewallOption {
+	return func(f *firewallClient) {
+		f.counters = counters
+	}
+}
+
+// StaticFirewallPolicy - returns FirewallPolicyFunc allowing the flows by the network service name
+func StaticFirewallPolicy(allowed map[string][]*vppacl.ACL_Rule) FirewallPolicyFunc {
+	return func(_ context.Context, networkService string) ([]*vppacl.ACL_Rule, error) {
+		return allowed[networkService], nil
+	}
+}
+
+type firewallClient struct {
+	policy   FirewallPolicyFunc
+	counters CountersFunc
+	// hits - the counters read last per acl name
+	hits map[string][]uint64
+	mu   sync.Mutex
+}
+
+// NewFirewallClient - creates a client chain element applying a default deny policy to the cross connected
+//                     interfaces: the allowed flows enter the incoming interface and leave the outgoing one reflexively,
+//                     so only their return traffic gets back through the outgoing interface.
+//                     It goes next to the xconnect in the client chain.
+func NewFirewallClient(policy FirewallPolicyFunc, opts ...FirewallOption) networkservice.NetworkServiceClient {
+	rv := &firewallClient{
+		policy: policy,
+		hits:   make(map[string][]uint64),
+	}
+	for _, opt := range opts {
+		opt(rv)
+	}
+	return rv
+}
+
+func (f *firewallClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	allowed, err := f.policy(ctx, request.GetConnection().GetNetworkService())
+	if err != nil {
+		return nil, err
+	}
+	ingress, egress, err := firewallRules(allowed)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	vppConfig := vppagent.Config(ctx).GetVppConfig()
+	incoming, outgoing, ok := xconnectedInterfaces(vppConfig.GetInterfaces())
+	if !ok {
+		return conn, nil
+	}
+	connID := conn.GetId() + firewallConnSuffix
+	known := globalACLs.holds(connID)
+	changed, err := globalACLs.attach(connID, incoming, &Policy{Ingress: ingress})
+	if err == nil {
+		var outgoingChanged []string
+		outgoingChanged, err = globalACLs.attach(connID, outgoing, &Policy{Ingress: denyAll(), Egress: egress})
+		changed = append(changed, outgoingChanged...)
+	}
+	if err != nil {
+		// Nothing has been applied for a new connection, so it releases the acls, a refreshed one keeps the committed
+		if !known {
+			globalACLs.detach(connID, incoming, outgoing)
+		}
+		_, _ = next.Client(ctx).Close(ctx, conn, opts...)
+		return nil, err
+	}
+	globalACLs.onCommit(ctx, changed)
+	if f.counters != nil {
+		f.reportCounters(conn, ingress)
+		// The acls exist in vpp only once they are committed
+		vppagent.AfterCommit(ctx, func() {
+			go f.readCounters(ingress)
+		})
+	}
+	return conn, nil
+}
+
+func (f *firewallClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	incoming, outgoing, _ := xconnectedInterfaces(vppagent.Config(ctx).GetVppConfig().GetInterfaces())
+	globalACLs.onCommit(ctx, globalACLs.detach(conn.GetId()+firewallConnSuffix, incoming, outgoing))
+	return rv, nil
+}
+
+// readCounters - reads the counters of the acls of the incoming interface
+func (f *firewallClient) readCounters(ingress []*vppacl.ACL_Rule) {
+	ctx, cancel := context.WithTimeout(context.Background(), countersTimeout)
+	defer cancel()
+
+	for _, rules := range [][]*vppacl.ACL_Rule{ingress, denyAll()} {
+		name, _ := aclName(rules)
+		hits, err := f.counters(ctx, name)
+		if err != nil {
+			log.Entry(ctx).Warnf("failed to read the counters of acl %s: %v", name, err)
+			continue
+		}
+		f.mu.Lock()
+		f.hits[name] = hits
+		f.mu.Unlock()
+	}
+}
+
+// reportCounters - puts the hits of the allowed rules and of the default deny read last into the metrics of the path
+// segment
+func (f *firewallClient) reportCounters(conn *networkservice.Connection, ingress []*vppacl.ACL_Rule) {
+	segments := conn.GetPath().GetPathSegments()
+	if int(conn.GetPath().GetIndex()) >= len(segments) {
+		return
+	}
+	ingressName, _ := aclName(ingress)
+	denyName, _ := aclName(denyAll())
+	f.mu.Lock()
+	ingressHits, ingressOk := f.hits[ingressName]
+	denyHits, denyOk := f.hits[denyName]
+	f.mu.Unlock()
+	if !ingressOk || !denyOk {
+		return
+	}
+	segment := segments[conn.GetPath().GetIndex()]
+	if segment.Metrics == nil {
+		segment.Metrics = make(map[string]string)
+	}
+	var defaultDenyHits uint64
+	for i, hits := range ingressHits {
+		if i < len(ingress)-len(denyAll()) {
+			segment.Metrics[fmt.Sprintf(firewallRuleHitsMetric, i)] = fmt.Sprint(hits)
+		} else {
+			defaultDenyHits += hits
+		}
+	}
+	for _, hits := range denyHits {
+		defaultDenyHits += hits
+	}
+	segment.Metrics[firewallDefaultDenyMetric] = fmt.Sprint(defaultDenyHits)
+}
+
+// firewallRules - returns the rules of the incoming interface ingress permitting the allowed flows and of the
+// outgoing interface egress reflecting them, both end with the default deny
+func firewallRules(allowed []*vppacl.ACL_Rule) (ingress, egress []*vppacl.ACL_Rule, err error) {
+	for i, rule := range allowed {
+		if rule.GetIpRule() == nil {
+			return nil, nil, errors.Errorf("firewall rule %d is not an IP rule", i)
+		}
+		ingressRule := proto.Clone(rule).(*vppacl.ACL_Rule)
+		egressRule := proto.Clone(rule).(*vppacl.ACL_Rule)
+		if rule.GetAction() != vppacl.ACL_Rule_DENY {
+			ingressRule.Action = vppacl.ACL_Rule_PERMIT
+			egressRule.Action = vppacl.ACL_Rule_REFLECT
+		}
+		ingress = append(ingress, ingressRule)
+		egress = append(egress, egressRule)
+	}
+	return append(ingress, denyAll()...), append(egress, denyAll()...), nil
+}
+
+func denyAll() []*vppacl.ACL_Rule {
+	var rv []*vppacl.ACL_Rule
+	for _, anyNet := range []string{"0.0.0.0/0", "::/0"} {
+		rv = append(rv, &vppacl.ACL_Rule{
+			Action: vppacl.ACL_Rule_DENY,
+			IpRule: &vppacl.ACL_Rule_IpRule{
+				Ip: &vppacl.ACL_Rule_IpRule_Ip{
+					DestinationNetwork: anyNet,
+					SourceNetwork:      anyNet,
+				},
+			},
+		})
+	}
+	return rv
+}
+
+// xconnectedInterfaces - returns the names of the incoming and the outgoing interfaces of the xconnect, the
+// sub-interfaces are cross connected instead of their parents
+func xconnectedInterfaces(interfaces []*vppinterfaces.Interface) (incoming, outgoing string, ok bool) {
+	parents := make(map[string]bool)
+	for _, iface := range interfaces {
+		if sub := iface.GetSub(); sub != nil {
+			parents[sub.GetParentName()] = true
+		}
+	}
+	var names []string
+	for i := len(interfaces) - 1; i >= 0 && len(names) < 2; i-- {
+		if !parents[interfaces[i].GetName()] {
+			names = append([]string{interfaces[i].GetName()}, names...)
+		}
+	}
+	if len(names) < 2 {
+		return "", "", false
+	}
+	return names[0], names[1], true
+}
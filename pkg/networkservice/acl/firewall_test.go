@@ -0,0 +1,202 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/acl"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func newFirewall(allowed map[string][]*vppacl.ACL_Rule, opts ...acl.FirewallOption) networkservice.NetworkServiceServer {
+	return chain.NewNetworkServiceServer(
+		memif.NewServer("BaseDir"),
+		adapters.NewClientToServer(chain.NewNetworkServiceClient(
+			acl.NewFirewallClient(acl.StaticFirewallPolicy(allowed), opts...),
+			memif.NewClient("BaseDir"),
+		)),
+	)
+}
+
+func denyAll() []*vppacl.ACL_Rule {
+	return []*vppacl.ACL_Rule{
+		{Action: vppacl.ACL_Rule_DENY, IpRule: &vppacl.ACL_Rule_IpRule{
+			Ip: &vppacl.ACL_Rule_IpRule_Ip{DestinationNetwork: "0.0.0.0/0", SourceNetwork: "0.0.0.0/0"},
+		}},
+		{Action: vppacl.ACL_Rule_DENY, IpRule: &vppacl.ACL_Rule_IpRule{
+			Ip: &vppacl.ACL_Rule_IpRule_Ip{DestinationNetwork: "::/0", SourceNetwork: "::/0"},
+		}},
+	}
+}
+
+func TestFirewallClient(t *testing.T) {
+	allowed, err := acl.MapToRules(map[string]string{
+		"1": "action=deny,dstnet=10.60.1.0/24",
+		"2": "action=permit,dstnet=10.60.0.0/16,tcplowport=80,tcpupport=80",
+	})
+	require.NoError(t, err)
+	server := newFirewall(map[string][]*vppacl.ACL_Rule{"ns": allowed})
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, request("fw", nil))
+	require.NoError(t, err)
+
+	ingress := append([]*vppacl.ACL_Rule{allowed[0], allowed[1]}, denyAll()...)
+	reflected := proto.Clone(allowed[1]).(*vppacl.ACL_Rule)
+	reflected.Action = vppacl.ACL_Rule_REFLECT
+	egress := append([]*vppacl.ACL_Rule{allowed[0], reflected}, denyAll()...)
+	update, _ := commit(ctx, t, false)
+	require.Len(t, update.GetVppConfig().GetAcls(), 3)
+	assert.Equal(t, &vppacl.ACL_Interfaces{Egress: []string{}, Ingress: []string{"server-fw"}}, findACL(update, ingress).GetInterfaces())
+	assert.Equal(t, &vppacl.ACL_Interfaces{Egress: []string{"client-fw"}, Ingress: []string{}}, findACL(update, egress).GetInterfaces())
+	assert.Equal(t, &vppacl.ACL_Interfaces{Egress: []string{}, Ingress: []string{"client-fw"}}, findACL(update, denyAll()).GetInterfaces())
+	assert.Equal(t, vppacl.ACL_Rule_PERMIT, allowed[1].GetAction())
+
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, conn)
+	require.NoError(t, err)
+	update, remove := commit(ctx, t, true)
+	assert.Nil(t, update)
+	assert.Len(t, remove.GetVppConfig().GetAcls(), 3)
+}
+
+func TestFirewallClientDefaultDeny(t *testing.T) {
+	server := newFirewall(nil)
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, request("fw-deny", nil))
+	require.NoError(t, err)
+	update, _ := commit(ctx, t, false)
+	require.Len(t, update.GetVppConfig().GetAcls(), 1)
+	assert.Equal(t, &vppacl.ACL_Interfaces{
+		Egress:  []string{"client-fw-deny"},
+		Ingress: []string{"client-fw-deny", "server-fw-deny"},
+	}, findACL(update, denyAll()).GetInterfaces())
+
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn)
+	require.NoError(t, err)
+}
+
+func TestFirewallClientCounters(t *testing.T) {
+	allowed, err := acl.MapToRules(map[string]string{
+		"1": "action=permit,dstnet=10.61.0.0/16",
+	})
+	require.NoError(t, err)
+	hits := map[string][]uint64{}
+	var mu sync.Mutex
+	server := newFirewall(map[string][]*vppacl.ACL_Rule{"ns": allowed}, acl.WithCountersFunc(
+		func(_ context.Context, aclName string) ([]uint64, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if counters, ok := hits[aclName]; ok {
+				return counters, nil
+			}
+			return nil, errors.Errorf("acl %s is not found", aclName)
+		}))
+
+	req := request("fw-counters", nil)
+	req.GetConnection().Path = &networkservice.Path{PathSegments: []*networkservice.PathSegment{{}}}
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, req)
+	require.NoError(t, err)
+	// Nothing is read before the acls are committed
+	assert.Empty(t, conn.GetPath().GetPathSegments()[0].GetMetrics())
+	update, _ := commit(ctx, t, false)
+	ingressName := findACL(update, append([]*vppacl.ACL_Rule{allowed[0]}, denyAll()...)).GetName()
+	denyName := findACL(update, denyAll()).GetName()
+
+	mu.Lock()
+	hits[ingressName] = []uint64{5, 1, 2}
+	hits[denyName] = []uint64{4, 0}
+	mu.Unlock()
+	ctx = vppagent.WithConfig(context.Background())
+	conn, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	commit(ctx, t, false)
+	// The counters read after the commit are reported on the next Request
+	require.Eventually(t, func() bool {
+		conn, err = server.Request(vppagent.WithConfig(context.Background()), &networkservice.NetworkServiceRequest{Connection: conn})
+		require.NoError(t, err)
+		return len(conn.GetPath().GetPathSegments()[0].GetMetrics()) > 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]string{
+		"firewall_rule_0_hits":       "5",
+		"firewall_default_deny_hits": "7",
+	}, conn.GetPath().GetPathSegments()[0].GetMetrics())
+
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn)
+	require.NoError(t, err)
+}
+
+func TestFirewallClientKeepsServerACLs(t *testing.T) {
+	allowed, err := acl.MapToRules(map[string]string{
+		"1": "action=permit,dstnet=10.62.0.0/16",
+	})
+	require.NoError(t, err)
+	rules := []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_DENY, "10.63.0.0/16")}
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer("BaseDir"),
+		acl.NewServer(rules),
+		adapters.NewClientToServer(chain.NewNetworkServiceClient(
+			acl.NewFirewallClient(acl.StaticFirewallPolicy(map[string][]*vppacl.ACL_Rule{"ns": allowed})),
+			memif.NewClient("BaseDir"),
+		)),
+	)
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, request("fw-server", nil))
+	require.NoError(t, err)
+	update, _ := commit(ctx, t, false)
+	ingress := append([]*vppacl.ACL_Rule{allowed[0]}, denyAll()...)
+	assert.Equal(t, []string{"server-fw-server"}, findACL(update, rules).GetInterfaces().GetIngress())
+	assert.Equal(t, []string{"server-fw-server"}, findACL(update, ingress).GetInterfaces().GetIngress())
+
+	// The refresh of the firewall doesn't detach the interface from the acl of the server
+	ctx = vppagent.WithConfig(context.Background())
+	conn, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	update, _ = commit(ctx, t, false)
+	assert.Equal(t, []string{"server-fw-server"}, findACL(update, rules).GetInterfaces().GetIngress())
+	assert.Equal(t, []string{"server-fw-server"}, findACL(update, ingress).GetInterfaces().GetIngress())
+
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn)
+	require.NoError(t, err)
+}
+
+func TestFirewallClientMacIPRule(t *testing.T) {
+	allowed, err := acl.MapToRules(map[string]string{
+		"1": "action=permit,srcmac=0a:1b:3c:4d:5e:6f",
+	})
+	require.NoError(t, err)
+	server := newFirewall(map[string][]*vppacl.ACL_Rule{"ns": allowed})
+	_, err = server.Request(vppagent.WithConfig(context.Background()), request("fw-macip", nil))
+	require.Error(t, err)
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+)
+
+const (
+	// IngressRuleKeyPrefix - prefix of the connection extra context and label keys holding ingress rules in the
+	//                        MapToRules format, for example "acl.ingress/allow-http": "action=permit,tcplowport=80,..."
+	IngressRuleKeyPrefix = "acl.ingress/"
+	// EgressRuleKeyPrefix - prefix of the connection extra context and label keys holding egress rules
+	EgressRuleKeyPrefix = "acl.egress/"
+)
+
+// Policy - ACL rules applied to the vpp interface of a connection, the first matching rule wins
+type Policy struct {
+	Ingress []*vppacl.ACL_Rule
+	Egress  []*vppacl.ACL_Rule
+}
+
+// PolicyFunc - looks up the policy for the network service and the labels of the client
+type PolicyFunc func(ctx context.Context, networkService string, labels map[string]string) (*Policy, error)
+
+// Option - option for NewServer
+type Option func(o *options)
+
+type options struct {
+	egress          []*vppacl.ACL_Rule
+	policy          PolicyFunc
+	connectionRules bool
+	reloadInterval  time.Duration
+}
+
+// WithEgressRules - sets the egress rules applied to every connection
+func WithEgressRules(rules []*vppacl.ACL_Rule) Option {
+	return func(o *options) {
+		o.egress = rules
+	}
+}
+
+// WithPolicyFunc - sets the func looking up the per-connection policy, it is called on every Request, so a refresh
+//                  picks up a changed policy
+func WithPolicyFunc(policy PolicyFunc) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+// WithConnectionRules - applies the rules found in the extra context and the labels of the connection, extra
+//                       context takes precedence for the same key. Clients get to choose their own rules, so they are
+//                       applied after the static and the looked up ones
+func WithConnectionRules() Option {
+	return func(o *options) {
+		o.connectionRules = true
+	}
+}
+
+// WithReloadInterval - sets how often NewServerFromFile checks the rules file for changes, 5s by default
+func WithReloadInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.reloadInterval = interval
+	}
+}
+
+// lookup - returns the static rules followed by the looked up rules followed by the connection rules
+func (o *options) lookup(ctx context.Context, static *Policy, conn *networkservice.Connection) (*Policy, error) {
+	rv := &Policy{
+		Ingress: append([]*vppacl.ACL_Rule{}, static.GetIngress()...),
+		Egress:  append([]*vppacl.ACL_Rule{}, static.GetEgress()...),
+	}
+	if o.policy != nil {
+		policy, err := o.policy(ctx, conn.GetNetworkService(), conn.GetLabels())
+		if err != nil {
+			return nil, err
+		}
+		rv.Ingress = append(rv.Ingress, policy.GetIngress()...)
+		rv.Egress = append(rv.Egress, policy.GetEgress()...)
+	}
+	if o.connectionRules {
+		values := make(map[string]string)
+		for k, v := range conn.GetLabels() {
+			values[k] = v
+		}
+		for k, v := range conn.GetContext().GetExtraContext() {
+			values[k] = v
+		}
+		rules, err := connectionRules(values, IngressRuleKeyPrefix)
+		if err != nil {
+			return nil, err
+		}
+		rv.Ingress = append(rv.Ingress, rules...)
+		if rules, err = connectionRules(values, EgressRuleKeyPrefix); err != nil {
+			return nil, err
+		}
+		rv.Egress = append(rv.Egress, rules...)
+	}
+	return rv, nil
+}
+
+// connectionRules - parses the rules with the key prefix in the order of the keys
+func connectionRules(values map[string]string, prefix string) ([]*vppacl.ACL_Rule, error) {
+	rules := make(map[string]string)
+	for k, v := range values {
+		if strings.HasPrefix(k, prefix) {
+			rules[k] = v
+		}
+	}
+	return MapToRules(rules)
+}
+
+// GetIngress - returns the ingress rules, nil safe
+func (p *Policy) GetIngress() []*vppacl.ACL_Rule {
+	if p == nil {
+		return nil
+	}
+	return p.Ingress
+}
+
+// GetEgress - returns the egress rules, nil safe
+func (p *Policy) GetEgress() []*vppacl.ACL_Rule {
+	if p == nil {
+		return nil
+	}
+	return p.Egress
+}
@@ -16,14 +16,17 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package acl provides a NetworkServiceServer chain element to apply an ingress acl
+// Package acl provides networkservice chain elements to apply ingress and egress acls and to firewall the cross
+// connected interfaces
 package acl
 
 import (
 	"context"
+	"sync"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
 
@@ -34,37 +37,134 @@ import (
 
 // ACL is a VPP Agent ACL composite
 type acl struct {
-	rules []*vppacl.ACL_Rule
+	// static - rules applied to every connection, they are replaced on the reload of the rules file
+	static  *Policy
+	options *options
+	// conns - connections with the acls applied
+	conns map[string]*aclConn
+	mu    sync.Mutex
 }
 
-// NewServer creates a NetworkServiceServer that applies an ingress acl specified by rules
-func NewServer(rules []*vppacl.ACL_Rule) networkservice.NetworkServiceServer {
-	return &acl{rules: rules}
+type aclConn struct {
+	conn      *networkservice.Connection
+	ifaceName string
+}
+
+// NewServer creates a NetworkServiceServer that applies an ingress acl specified by rules, options add egress
+// rules and per-connection policy. The interfaces with the same rules share a single acl.
+func NewServer(rules []*vppacl.ACL_Rule, opts ...Option) networkservice.NetworkServiceServer {
+	return newACL(rules, opts...)
+}
+
+func newACL(rules []*vppacl.ACL_Rule, opts ...Option) *acl {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &acl{
+		static:  &Policy{Ingress: rules, Egress: o.egress},
+		options: o,
+		conns:   make(map[string]*aclConn),
+	}
 }
 
 func (a *acl) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
 	conf := vppagent.Config(ctx)
-	a.appendACLConfig(conf)
-	return next.Server(ctx).Request(ctx, request)
+	ifaceName, ok := lastInterfaceName(conf)
+	if !ok {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	a.mu.Lock()
+	static := a.static
+	a.mu.Unlock()
+	policy, err := a.options.lookup(ctx, static, request.GetConnection())
+	if err != nil {
+		return nil, err
+	}
+	connID := request.GetConnection().GetId()
+	changed, err := globalACLs.attach(connID, ifaceName, policy)
+	if err != nil {
+		return nil, err
+	}
+	globalACLs.onCommit(ctx, changed)
+	conn, err := next.Server(ctx).Request(ctx, request)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err != nil {
+		// Nothing has been applied for a new connection, so it releases the acls
+		if _, known := a.conns[connID]; !known {
+			globalACLs.detach(connID, ifaceName)
+		}
+		return nil, err
+	}
+	a.conns[connID] = &aclConn{
+		conn:      conn.Clone(),
+		ifaceName: ifaceName,
+	}
+	return conn, nil
 }
 
 func (a *acl) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	a.mu.Lock()
+	delete(a.conns, conn.GetId())
+	a.mu.Unlock()
 	conf := vppagent.Config(ctx)
-	a.appendACLConfig(conf)
+	ifaceName, _ := lastInterfaceName(conf)
+	globalACLs.onCommit(ctx, globalACLs.detach(conn.GetId(), ifaceName))
 	return next.Server(ctx).Close(ctx, conn)
 }
 
-func (a *acl) appendACLConfig(conf *configurator.Config) {
-	if a.rules != nil && len(conf.GetVppConfig().GetInterfaces()) > 0 {
-		// TODO - this can likely be changed into just a single ACL, with appending new interface to which it
-		// can be applied
-		conf.GetVppConfig().Acls = append(conf.GetVppConfig().Acls, &vppacl.ACL{
-			Name:  "ingress-acl-" + conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].GetName(),
-			Rules: a.rules,
-			Interfaces: &vppacl.ACL_Interfaces{
-				Egress:  []string{},
-				Ingress: []string{conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].GetName()},
-			},
-		})
+// connPolicy - policy of a live connection looked up for the reload of the rules file
+type connPolicy struct {
+	conn   *aclConn
+	policy *Policy
+}
+
+// lookupAll - looks up the policies of all the live connections with the static rules. The policies are looked up
+// without holding a.mu, the PolicyFunc may take its time
+func (a *acl) lookupAll(ctx context.Context, static *Policy) map[string]*connPolicy {
+	a.mu.Lock()
+	conns := make(map[string]*aclConn, len(a.conns))
+	for connID, c := range a.conns {
+		conns[connID] = c
+	}
+	a.mu.Unlock()
+
+	rv := make(map[string]*connPolicy, len(conns))
+	for connID, c := range conns {
+		policy, err := a.options.lookup(ctx, static, c.conn)
+		if err != nil {
+			log.Entry(ctx).Warnf("failed to update the acls of the connection %s: %v", connID, err)
+			continue
+		}
+		rv[connID] = &connPolicy{conn: c, policy: policy}
+	}
+	return rv
+}
+
+// reattach - attaches the interfaces of the live connections to the acls of their policies in acls, returns the names
+// of the changed acls. The connections requested or closed since the policies were looked up are left alone, they
+// have been attached with the current rules. The caller holds a.mu
+func (a *acl) reattach(ctx context.Context, acls *sharedACLs, policies map[string]*connPolicy) []string {
+	var changed []string
+	for connID, p := range policies {
+		if a.conns[connID] != p.conn {
+			continue
+		}
+		connChanged, err := acls.attach(connID, p.conn.ifaceName, p.policy)
+		if err != nil {
+			log.Entry(ctx).Warnf("failed to update the acls of the connection %s: %v", connID, err)
+			continue
+		}
+		changed = append(changed, connChanged...)
+	}
+	return changed
+}
+
+func lastInterfaceName(conf *configurator.Config) (string, bool) {
+	interfaces := conf.GetVppConfig().GetInterfaces()
+	if len(interfaces) == 0 {
+		return "", false
 	}
+	return interfaces[len(interfaces)-1].GetName(), true
 }
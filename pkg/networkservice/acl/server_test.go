@@ -0,0 +1,262 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl_test
+
+import (
+	"context"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	memif_mechanisms "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/acl"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func request(id string, labels map[string]string) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id:             id,
+			NetworkService: "ns",
+			Mechanism: &networkservice.Mechanism{
+				Cls:        cls.LOCAL,
+				Type:       memif_mechanisms.MECHANISM,
+				Parameters: map[string]string{memif_mechanisms.SocketFilename: "socketfilename"},
+			},
+			Labels:  labels,
+			Context: &networkservice.ConnectionContext{},
+		},
+	}
+}
+
+func rule(action vppacl.ACL_Rule_Action, dstNet string) *vppacl.ACL_Rule {
+	return &vppacl.ACL_Rule{
+		Action: action,
+		IpRule: &vppacl.ACL_Rule_IpRule{
+			Ip: &vppacl.ACL_Rule_IpRule_Ip{DestinationNetwork: dstNet},
+		},
+	}
+}
+
+func newServer(rules []*vppacl.ACL_Rule, opts ...acl.Option) networkservice.NetworkServiceServer {
+	logrus.SetOutput(ioutil.Discard)
+	return chain.NewNetworkServiceServer(
+		memif.NewServer("BaseDir"),
+		acl.NewServer(rules, opts...),
+	)
+}
+
+// commit - returns the configs committed for ctx
+func commit(ctx context.Context, t *testing.T, isClose bool) (update, remove *configurator.Config) {
+	require.NoError(t, vppagent.Commit(ctx, isClose, func(u, r *configurator.Config) error {
+		update, remove = u, r
+		return nil
+	}))
+	return update, remove
+}
+
+// findACL - returns the acl of the config with the rules
+func findACL(config *configurator.Config, rules []*vppacl.ACL_Rule) *vppacl.ACL {
+	for _, a := range config.GetVppConfig().GetAcls() {
+		if reflect.DeepEqual(a.GetRules(), rules) {
+			return a
+		}
+	}
+	return nil
+}
+
+func TestACLServerStaticRules(t *testing.T) {
+	ingress := []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_DENY, "10.10.0.0/16")}
+	egress := []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_PERMIT, "10.20.0.0/16")}
+	server := newServer(ingress, acl.WithEgressRules(egress))
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, request("static", nil))
+	require.NoError(t, err)
+	update, _ := commit(ctx, t, false)
+	require.Len(t, update.GetVppConfig().GetAcls(), 2)
+	assert.Equal(t, &vppacl.ACL_Interfaces{Egress: []string{}, Ingress: []string{"server-static"}}, findACL(update, ingress).GetInterfaces())
+	assert.Equal(t, &vppacl.ACL_Interfaces{Egress: []string{"server-static"}, Ingress: []string{}}, findACL(update, egress).GetInterfaces())
+
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, conn)
+	require.NoError(t, err)
+	update, remove := commit(ctx, t, true)
+	assert.Nil(t, update)
+	assert.Len(t, remove.GetVppConfig().GetAcls(), 2)
+}
+
+func TestACLServerSharedACL(t *testing.T) {
+	rules := []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_DENY, "10.30.0.0/16")}
+	server := newServer(rules)
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn1, err := server.Request(ctx, request("shared-1", nil))
+	require.NoError(t, err)
+	ctx = vppagent.WithConfig(context.Background())
+	conn2, err := server.Request(ctx, request("shared-2", nil))
+	require.NoError(t, err)
+	update, _ := commit(ctx, t, false)
+	require.Len(t, update.GetVppConfig().GetAcls(), 1)
+	shared := findACL(update, rules)
+	assert.Equal(t, []string{"server-shared-1", "server-shared-2"}, shared.GetInterfaces().GetIngress())
+
+	// The acl is still used by the second connection, it is updated without the closed interface
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, conn1)
+	require.NoError(t, err)
+	update, remove := commit(ctx, t, true)
+	assert.Empty(t, remove.GetVppConfig().GetAcls())
+	require.Len(t, update.GetVppConfig().GetAcls(), 1)
+	assert.Equal(t, []string{"server-shared-2"}, findACL(update, rules).GetInterfaces().GetIngress())
+
+	// Refresh of the second connection keeps the interfaces of the acl
+	ctx = vppagent.WithConfig(context.Background())
+	conn2, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn2})
+	require.NoError(t, err)
+	update, _ = commit(ctx, t, false)
+	assert.Equal(t, []string{"server-shared-2"}, findACL(update, rules).GetInterfaces().GetIngress())
+
+	// The last connection deletes the acl
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, conn2)
+	require.NoError(t, err)
+	update, remove = commit(ctx, t, true)
+	assert.Nil(t, update)
+	require.Len(t, remove.GetVppConfig().GetAcls(), 1)
+	assert.Equal(t, shared.GetName(), remove.GetVppConfig().GetAcls()[0].GetName())
+}
+
+func TestACLServerCommitsCurrentState(t *testing.T) {
+	rules := []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_DENY, "10.31.0.0/16")}
+	server := newServer(rules)
+
+	ctx1 := vppagent.WithConfig(context.Background())
+	conn1, err := server.Request(ctx1, request("current-1", nil))
+	require.NoError(t, err)
+	ctx2 := vppagent.WithConfig(context.Background())
+	conn2, err := server.Request(ctx2, request("current-2", nil))
+	require.NoError(t, err)
+
+	// The commit of the first Request coming last doesn't send its stale interface list
+	update, _ := commit(ctx2, t, false)
+	assert.Equal(t, []string{"server-current-1", "server-current-2"}, findACL(update, rules).GetInterfaces().GetIngress())
+	update, _ = commit(ctx1, t, false)
+	assert.Equal(t, []string{"server-current-1", "server-current-2"}, findACL(update, rules).GetInterfaces().GetIngress())
+
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn1)
+	require.NoError(t, err)
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn2)
+	require.NoError(t, err)
+}
+
+func TestACLServerPolicyFuncRefresh(t *testing.T) {
+	static := rule(vppacl.ACL_Rule_DENY, "10.40.0.0/16")
+	policies := map[string]*acl.Policy{
+		"gold": {
+			Ingress: []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_PERMIT, "0.0.0.0/0")},
+			Egress:  []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_PERMIT, "0.0.0.0/0")},
+		},
+		"bronze": {
+			Ingress: []*vppacl.ACL_Rule{rule(vppacl.ACL_Rule_PERMIT, "192.168.0.0/16")},
+		},
+	}
+	server := newServer([]*vppacl.ACL_Rule{static}, acl.WithPolicyFunc(
+		func(_ context.Context, networkService string, labels map[string]string) (*acl.Policy, error) {
+			assert.Equal(t, "ns", networkService)
+			return policies[labels["tier"]], nil
+		}))
+	goldIngress := append([]*vppacl.ACL_Rule{static}, policies["gold"].Ingress...)
+	bronzeIngress := append([]*vppacl.ACL_Rule{static}, policies["bronze"].Ingress...)
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, request("policy", map[string]string{"tier": "gold"}))
+	require.NoError(t, err)
+	update, _ := commit(ctx, t, false)
+	assert.Equal(t, []string{"server-policy"}, findACL(update, goldIngress).GetInterfaces().GetIngress())
+	assert.Equal(t, []string{"server-policy"}, findACL(update, policies["gold"].Egress).GetInterfaces().GetEgress())
+
+	// Refresh with a changed policy attaches the interface to the new acl and detaches it from the old ones
+	conn.Labels["tier"] = "bronze"
+	ctx = vppagent.WithConfig(context.Background())
+	conn, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	update, remove := commit(ctx, t, false)
+	require.Len(t, update.GetVppConfig().GetAcls(), 1)
+	assert.Equal(t, []string{"server-policy"}, findACL(update, bronzeIngress).GetInterfaces().GetIngress())
+	// The acls left with no interfaces are deleted after the Update
+	assert.Len(t, remove.GetVppConfig().GetAcls(), 2)
+
+	// Close deletes all the acls held by the connection
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, conn)
+	require.NoError(t, err)
+	update, remove = commit(ctx, t, true)
+	assert.Nil(t, update)
+	assert.Len(t, remove.GetVppConfig().GetAcls(), 1)
+}
+
+func TestACLServerPolicyFuncError(t *testing.T) {
+	server := newServer(nil, acl.WithPolicyFunc(
+		func(context.Context, string, map[string]string) (*acl.Policy, error) {
+			return nil, errors.New("no policy")
+		}))
+	_, err := server.Request(vppagent.WithConfig(context.Background()), request("error", nil))
+	require.Error(t, err)
+}
+
+func TestACLServerConnectionRules(t *testing.T) {
+	server := newServer(nil, acl.WithConnectionRules())
+
+	req := request("labels", map[string]string{
+		acl.IngressRuleKeyPrefix + "b": "action=permit,dstnet=10.50.0.0/16",
+		acl.IngressRuleKeyPrefix + "a": "action=deny,dstnet=10.51.0.0/16",
+		acl.EgressRuleKeyPrefix + "a":  "action=deny,dstnet=10.52.0.0/16",
+		"app":                          "web",
+	})
+	req.GetConnection().GetContext().ExtraContext = map[string]string{
+		acl.EgressRuleKeyPrefix + "a": "action=permit,dstnet=10.53.0.0/16",
+	}
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, req)
+	require.NoError(t, err)
+	update, _ := commit(ctx, t, false)
+	assert.NotNil(t, findACL(update, []*vppacl.ACL_Rule{
+		rule(vppacl.ACL_Rule_DENY, "10.51.0.0/16"),
+		rule(vppacl.ACL_Rule_PERMIT, "10.50.0.0/16"),
+	}))
+	assert.NotNil(t, findACL(update, []*vppacl.ACL_Rule{
+		rule(vppacl.ACL_Rule_PERMIT, "10.53.0.0/16"),
+	}))
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn)
+	require.NoError(t, err)
+
+	req = request("labels", map[string]string{acl.IngressRuleKeyPrefix + "a": "action=drop"})
+	_, err = server.Request(vppagent.WithConfig(context.Background()), req)
+	require.Error(t, err)
+}
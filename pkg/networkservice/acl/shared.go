@@ -0,0 +1,274 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const aclNamePrefix = "acl-"
+
+// sharedACLs - an acl is shared by all the interfaces with the same rules: it is created once and the interfaces are
+// attached to it and detached from it per connection. vpp acls are global, so the acls of the process are kept here
+// and the acls changed by a connection are put into its configs with their current interfaces when they are committed
+type sharedACLs struct {
+	acls map[string]*sharedACL
+	mu   sync.Mutex
+}
+
+type sharedACL struct {
+	rules   []*vppacl.ACL_Rule
+	ingress map[string]bool
+	egress  map[string]bool
+	// conns - ids of the connections holding the acl, it is deleted when the last of them closes
+	conns map[string]bool
+}
+
+var globalACLs = &sharedACLs{
+	acls: make(map[string]*sharedACL),
+}
+
+// attach - attaches the interface of the connection to the acls of the policy and detaches it from the other acls
+// held by the connection, returns the names of the changed acls: the acls left with no interfaces are deleted, the
+// commit removes them after the Update attaching the interface to the new ones
+func (s *sharedACLs) attach(connID, ifaceName string, policy *Policy) ([]string, error) {
+	if err := validatePolicy(policy); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ingress, err := aclName(policy.GetIngress())
+	if err != nil {
+		return nil, err
+	}
+	egress, err := aclName(policy.GetEgress())
+	if err != nil {
+		return nil, err
+	}
+	s.create(ingress, policy.GetIngress())
+	s.create(egress, policy.GetEgress())
+	var changed []string
+	for _, name := range s.names() {
+		a := s.acls[name]
+		held := name == ingress || name == egress
+		if !held && !a.conns[connID] {
+			continue
+		}
+		if held || a.ingress[ifaceName] || a.egress[ifaceName] {
+			changed = append(changed, name)
+		}
+		delete(a.ingress, ifaceName)
+		delete(a.egress, ifaceName)
+		if name == ingress {
+			a.ingress[ifaceName] = true
+		}
+		if name == egress {
+			a.egress[ifaceName] = true
+		}
+		if held {
+			a.conns[connID] = true
+		} else if len(a.ingress) == 0 && len(a.egress) == 0 {
+			delete(s.acls, name)
+		}
+	}
+	return changed, nil
+}
+
+// detach - detaches the interfaces and releases the acls held by the connection, returns the names of the changed
+// acls: the acls released by their last connection are deleted, the others lose the interfaces
+func (s *sharedACLs) detach(connID string, ifaceNames ...string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changed []string
+	for _, name := range s.names() {
+		a := s.acls[name]
+		if !a.conns[connID] {
+			continue
+		}
+		for _, ifaceName := range ifaceNames {
+			delete(a.ingress, ifaceName)
+			delete(a.egress, ifaceName)
+		}
+		delete(a.conns, connID)
+		if len(a.conns) == 0 {
+			delete(s.acls, name)
+		}
+		changed = append(changed, name)
+	}
+	return changed
+}
+
+// holds - returns true if the connection holds any acl
+func (s *sharedACLs) holds(connID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range s.acls {
+		if a.conns[connID] {
+			return true
+		}
+	}
+	return false
+}
+
+// prune - removes the acls with no interfaces attached, returns their names, for the callers able to delete them
+// before their connections close
+func (s *sharedACLs) prune() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pruned []string
+	for _, name := range s.names() {
+		if a := s.acls[name]; len(a.ingress) == 0 && len(a.egress) == 0 {
+			delete(s.acls, name)
+			pruned = append(pruned, name)
+		}
+	}
+	return pruned
+}
+
+// onCommit - puts the current state of the acls into the configs of ctx when they are committed: the acls in use
+// are updated with their current interfaces and the deleted ones are removed, so the commits serialized by
+// vppagent.Commit never send a stale interface list last
+func (s *sharedACLs) onCommit(ctx context.Context, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	vppagent.OnCommit(ctx, func(configs *vppagent.CommitConfigs) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.put(configs, names)
+	})
+}
+
+// put - puts the acls in use into the update config and the deleted ones into the remove config, the caller holds s.mu
+func (s *sharedACLs) put(configs *vppagent.CommitConfigs, names []string) {
+	for _, name := range names {
+		if a, ok := s.acls[name]; ok {
+			putACL(configs.Update().GetVppConfig(), a.config(name))
+		} else {
+			putACL(configs.Remove().GetVppConfig(), &vppacl.ACL{Name: name})
+		}
+	}
+}
+
+// clone - returns a copy of the acls to be changed and swapped in once the changes are committed, the caller holds s.mu
+func (s *sharedACLs) clone() *sharedACLs {
+	rv := &sharedACLs{
+		acls: make(map[string]*sharedACL, len(s.acls)),
+	}
+	for name, a := range s.acls {
+		rv.acls[name] = &sharedACL{
+			rules:   a.rules,
+			ingress: copySet(a.ingress),
+			egress:  copySet(a.egress),
+			conns:   copySet(a.conns),
+		}
+	}
+	return rv
+}
+
+// aclName - returns the name of the acl with the rules, no rules have no acl
+func aclName(rules []*vppacl.ACL_Rule) (string, error) {
+	if len(rules) == 0 {
+		return "", nil
+	}
+	h := sha256.New()
+	for _, rule := range rules {
+		data, err := proto.Marshal(rule)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to marshal acl rule %v", rule)
+		}
+		_, _ = h.Write(data)
+		_, _ = h.Write([]byte{0})
+	}
+	return aclNamePrefix + hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+func (s *sharedACLs) create(name string, rules []*vppacl.ACL_Rule) {
+	if _, ok := s.acls[name]; name == "" || ok {
+		return
+	}
+	s.acls[name] = &sharedACL{
+		rules:   rules,
+		ingress: make(map[string]bool),
+		egress:  make(map[string]bool),
+		conns:   make(map[string]bool),
+	}
+}
+
+// putACL - puts the acl into vppConfig replacing the one with the same name
+func putACL(vppConfig *vpp.ConfigData, acl *vppacl.ACL) {
+	for i, a := range vppConfig.GetAcls() {
+		if a.GetName() == acl.GetName() {
+			vppConfig.Acls[i] = acl
+			return
+		}
+	}
+	vppConfig.Acls = append(vppConfig.Acls, acl)
+}
+
+func (s *sharedACLs) names() []string {
+	names := make([]string, 0, len(s.acls))
+	for name := range s.acls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (a *sharedACL) config(name string) *vppacl.ACL {
+	return &vppacl.ACL{
+		Name:  name,
+		Rules: a.rules,
+		Interfaces: &vppacl.ACL_Interfaces{
+			Egress:  sortedKeys(a.egress),
+			Ingress: sortedKeys(a.ingress),
+		},
+	}
+}
+
+func copySet(m map[string]bool) map[string]bool {
+	rv := make(map[string]bool, len(m))
+	for k, v := range m {
+		rv[k] = v
+	}
+	return rv
+}
+
+func sortedKeys(m map[string]bool) []string {
+	rv := make([]string, 0, len(m))
+	for k := range m {
+		rv = append(rv, k)
+	}
+	sort.Strings(rv)
+	return rv
+}
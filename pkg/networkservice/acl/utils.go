@@ -18,6 +18,7 @@ package acl
 
 import (
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -25,37 +26,136 @@ import (
 	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
 )
 
+// Rule keys, a rule is a comma separated list of key=value pairs, for example
+// "action=permit,srcnet=10.0.0.0/8,tcplowport=80,tcpupport=8080". Ranges are "first-last" or a single value.
+// The icmp, tcp and udp keys are mutually exclusive and exclude proto, a rule with srcmac is a MAC-IP rule allowing
+// only the action, srcnet, srcmac and srcmacmask keys. A list of rules can't mix IP and MAC-IP rules, MAC-IP rules
+// are ingress only.
 const (
-	action     = "action"     // DENY, PERMIT, REFLECT
-	dstNet     = "dstnet"     // IPv4 or IPv6 CIDR
-	srcNet     = "srcnet"     // IPv4 or IPv6 CIDR
-	icmpType   = "icmptype"   // 8-bit unsigned integer
-	tcpLowPort = "tcplowport" // 16-bit unsigned integer
-	tcpUpPort  = "tcpupport"  // 16-bit unsigned integer
-	udpLowPort = "udplowport" // 16-bit unsigned integer
-	udpUpPort  = "udpupport"  // 16-bit unsigned integer
+	action        = "action"        // DENY, PERMIT, REFLECT
+	dstNet        = "dstnet"        // IPv4 or IPv6 CIDR
+	srcNet        = "srcnet"        // IPv4 or IPv6 CIDR
+	protocol      = "proto"         // 8-bit unsigned integer, not allowed with the icmp, tcp and udp keys
+	icmpV6        = "icmpv6"        // bool, ICMPv6 instead of ICMP
+	icmpType      = "icmptype"      // 8-bit unsigned integer range
+	icmpCode      = "icmpcode"      // 8-bit unsigned integer range
+	tcpLowPort    = "tcplowport"    // 16-bit unsigned integer, destination port range
+	tcpUpPort     = "tcpupport"     // 16-bit unsigned integer
+	tcpSrcLowPort = "tcpsrclowport" // 16-bit unsigned integer, source port range
+	tcpSrcUpPort  = "tcpsrcupport"  // 16-bit unsigned integer
+	tcpFlagsMask  = "tcpflagsmask"  // 8-bit unsigned integer
+	tcpFlagsValue = "tcpflagsvalue" // 8-bit unsigned integer, bits outside of the mask are not allowed
+	udpLowPort    = "udplowport"    // 16-bit unsigned integer, destination port range
+	udpUpPort     = "udpupport"     // 16-bit unsigned integer
+	udpSrcLowPort = "udpsrclowport" // 16-bit unsigned integer, source port range
+	udpSrcUpPort  = "udpsrcupport"  // 16-bit unsigned integer
+	srcMac        = "srcmac"        // MAC address
+	srcMacMask    = "srcmacmask"    // MAC address mask, ff:ff:ff:ff:ff:ff by default
 )
 
-// MapToRules converts a map[string]string of rules to a []*vppacl.ACL_Rule
+var (
+	icmpKeys  = []string{icmpV6, icmpType, icmpCode}
+	tcpKeys   = []string{tcpLowPort, tcpUpPort, tcpSrcLowPort, tcpSrcUpPort, tcpFlagsMask, tcpFlagsValue}
+	udpKeys   = []string{udpLowPort, udpUpPort, udpSrcLowPort, udpSrcUpPort}
+	macIPKeys = []string{action, srcNet, srcMac, srcMacMask}
+	ruleKeys  = keySet(append(append(append([]string{action, dstNet, srcNet, protocol, srcMac, srcMacMask},
+		icmpKeys...), tcpKeys...), udpKeys...))
+)
+
+const (
+	maxPort        = 65535
+	defaultMacMask = "ff:ff:ff:ff:ff:ff"
+)
+
+// MapToRules converts a map[string]string of rules to a []*vppacl.ACL_Rule ordered by the rule keys, numeric keys
+// are ordered numerically and go first
 func MapToRules(rules map[string]string) ([]*vppacl.ACL_Rule, error) {
 	rv := []*vppacl.ACL_Rule{}
+	for _, key := range sortedRuleKeys(rules) {
+		rule, err := parseRule(rules[key])
+		if err != nil {
+			return nil, errors.Errorf("parsing rule %s [%s] failed with: %v", key, rules[key], err)
+		}
+		rv = append(rv, rule)
+	}
+	if err := validateRules(rv); err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
+
+// validateRules - vpp acls are either IP or MAC-IP acls, so the rules of an acl can't mix them
+func validateRules(rules []*vppacl.ACL_Rule) error {
+	var macIP int
 	for _, rule := range rules {
-		parsed := parseKVStringToMap(rule, ",", "=")
+		if rule.GetMacipRule() != nil {
+			macIP++
+		}
+	}
+	if macIP != 0 && macIP != len(rules) {
+		return errors.New("IP and MAC-IP rules can't be mixed")
+	}
+	return nil
+}
 
-		action, err := getAction(parsed)
-		if err != nil {
-			return nil, errors.Errorf("parsing rule %s failed with %v", rule, err)
+// validatePolicy - validates the rules of both directions, vpp applies MAC-IP acls to the ingress only
+func validatePolicy(policy *Policy) error {
+	if err := validateRules(policy.GetIngress()); err != nil {
+		return errors.Wrap(err, "invalid ingress rules")
+	}
+	if err := validateRules(policy.GetEgress()); err != nil {
+		return errors.Wrap(err, "invalid egress rules")
+	}
+	for _, rule := range policy.GetEgress() {
+		if rule.GetMacipRule() != nil {
+			return errors.New("MAC-IP rules can't be applied to the egress")
 		}
+	}
+	return nil
+}
 
-		match, err := getMatch(parsed)
-		if err != nil {
-			return nil, errors.Errorf("parsing rule %s failed with %v", rule, err)
+func sortedRuleKeys(rules map[string]string) []string {
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, iErr := strconv.ParseUint(keys[i], 10, 64)
+		nj, jErr := strconv.ParseUint(keys[j], 10, 64)
+		switch {
+		case iErr == nil && jErr == nil && ni != nj:
+			return ni < nj
+		case (iErr == nil) != (jErr == nil):
+			return iErr == nil
 		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
 
-		match.Action = action
-		rv = append(rv, match)
+func parseRule(rule string) (*vppacl.ACL_Rule, error) {
+	parsed, err := parseKVStringToMap(rule, ",", "=")
+	if err != nil {
+		return nil, err
 	}
-	return rv, nil
+	for key := range parsed {
+		if !ruleKeys[key] {
+			return nil, errors.Errorf("unknown key '%s'", key)
+		}
+	}
+
+	action, err := getAction(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := getMatch(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	match.Action = action
+	return match, nil
 }
 
 func getAction(parsed map[string]string) (vppacl.ACL_Rule_Action, error) {
@@ -65,7 +165,7 @@ func getAction(parsed map[string]string) (vppacl.ACL_Rule_Action, error) {
 	}
 	action, ok := vppacl.ACL_Rule_Action_value[strings.ToUpper(actionName)]
 	if !ok {
-		return vppacl.ACL_Rule_Action(0), errors.New("rule should have a valid 'action'")
+		return vppacl.ACL_Rule_Action(0), errors.Errorf("rule should have a valid 'action', got '%s'", actionName)
 	}
 	return vppacl.ACL_Rule_Action(action), nil
 }
@@ -91,107 +191,175 @@ func getIP(parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Ip, error) {
 		srcNet = ""
 	}
 
-	if dstNetOk || srcNetOk {
+	proto, protoOk, err := getUint(protocol, parsed, 8)
+	if err != nil {
+		return nil, err
+	}
+
+	if dstNetOk || srcNetOk || protoOk {
 		return &vppacl.ACL_Rule_IpRule_Ip{
 			DestinationNetwork: dstNet,
 			SourceNetwork:      srcNet,
+			Protocol:           proto,
 		}, nil
 	}
 	return nil, nil
 }
 
 func getICMP(parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Icmp, error) {
-	icmpType, ok := parsed[icmpType]
-	if !ok {
+	if !hasAnyKey(parsed, icmpKeys) {
 		return nil, nil
 	}
-	icmpType8, err := strconv.ParseUint(icmpType, 10, 8)
+	var icmpv6 bool
+	if value, ok := parsed[icmpV6]; ok {
+		var err error
+		if icmpv6, err = strconv.ParseBool(value); err != nil {
+			return nil, errors.Errorf("failed parsing %s [%v] with: %v", icmpV6, value, err)
+		}
+	}
+	typeRange, err := getICMPRange(icmpType, parsed)
+	if err != nil {
+		return nil, err
+	}
+	codeRange, err := getICMPRange(icmpCode, parsed)
 	if err != nil {
-		return nil, errors.Errorf("failed parsing icmptype [%v] with: %v", icmpType, err)
+		return nil, err
 	}
 	return &vppacl.ACL_Rule_IpRule_Icmp{
-		Icmpv6: false,
-		IcmpCodeRange: &vppacl.ACL_Rule_IpRule_Icmp_Range{
+		Icmpv6:        icmpv6,
+		IcmpCodeRange: codeRange,
+		IcmpTypeRange: typeRange,
+	}, nil
+}
+
+// getICMPRange - parses "first-last" or a single value, all the values by default
+func getICMPRange(name string, parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Icmp_Range, error) {
+	value, ok := parsed[name]
+	if !ok {
+		return &vppacl.ACL_Rule_IpRule_Icmp_Range{
 			First: uint32(0),
 			Last:  uint32(65535),
-		},
-		IcmpTypeRange: &vppacl.ACL_Rule_IpRule_Icmp_Range{
-			First: uint32(icmpType8),
-			Last:  uint32(icmpType8),
-		},
+		}, nil
+	}
+	bounds := strings.SplitN(value, "-", 2)
+	first, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 8)
+	if err != nil {
+		return nil, errors.Errorf("failed parsing %s [%v] with: %v", name, value, err)
+	}
+	last := first
+	if len(bounds) == 2 {
+		if last, err = strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 8); err != nil {
+			return nil, errors.Errorf("failed parsing %s [%v] with: %v", name, value, err)
+		}
+	}
+	if first > last {
+		return nil, errors.Errorf("%s [%v] is an empty range", name, value)
+	}
+	return &vppacl.ACL_Rule_IpRule_Icmp_Range{
+		First: uint32(first),
+		Last:  uint32(last),
 	}, nil
 }
 
-func getPort(name string, parsed map[string]string) (port uint16, found bool, err error) {
-	portString, ok := parsed[name]
+func getUint(name string, parsed map[string]string, bitSize int) (value uint32, found bool, err error) {
+	valueString, ok := parsed[name]
 	if !ok {
 		return 0, false, nil
 	}
-	port16, err := strconv.ParseUint(portString, 10, 16)
+	value64, err := strconv.ParseUint(valueString, 10, bitSize)
 	if err != nil {
-		return 0, true, errors.Errorf("failed parsing %s [%v] with: %v", name, portString, err)
+		return 0, true, errors.Errorf("failed parsing %s [%v] with: %v", name, valueString, err)
 	}
+	return uint32(value64), true, nil
+}
 
-	return uint16(port16), true, nil
+// getPortRange - parses the port range, the lower port is 0 and the upper port is 65535 by default
+func getPortRange(lowName, upName string, parsed map[string]string) (*vppacl.ACL_Rule_IpRule_PortRange, error) {
+	lowerPort, _, err := getUint(lowName, parsed, 16)
+	if err != nil {
+		return nil, err
+	}
+	upperPort, upFound, err := getUint(upName, parsed, 16)
+	if err != nil {
+		return nil, err
+	}
+	if !upFound {
+		upperPort = maxPort
+	}
+	if lowerPort > upperPort {
+		return nil, errors.Errorf("%s [%d] is greater than %s [%d]", lowName, lowerPort, upName, upperPort)
+	}
+	return &vppacl.ACL_Rule_IpRule_PortRange{
+		LowerPort: lowerPort,
+		UpperPort: upperPort,
+	}, nil
 }
 
 func getTCP(parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Tcp, error) {
-	lowerPort, lpFound, lpErr := getPort(tcpLowPort, parsed)
-	if !lpFound {
+	if !hasAnyKey(parsed, tcpKeys) {
 		return nil, nil
-	} else if lpErr != nil {
-		return nil, lpErr
 	}
-
-	upperPort, upFound, upErr := getPort(tcpUpPort, parsed)
-	if !upFound {
-		return nil, nil
-	} else if upErr != nil {
-		return nil, lpErr
+	dstRange, err := getPortRange(tcpLowPort, tcpUpPort, parsed)
+	if err != nil {
+		return nil, err
+	}
+	srcRange, err := getPortRange(tcpSrcLowPort, tcpSrcUpPort, parsed)
+	if err != nil {
+		return nil, err
+	}
+	mask, _, err := getUint(tcpFlagsMask, parsed, 8)
+	if err != nil {
+		return nil, err
+	}
+	value, _, err := getUint(tcpFlagsValue, parsed, 8)
+	if err != nil {
+		return nil, err
+	}
+	if value&^mask != 0 {
+		return nil, errors.Errorf("%s [%d] has bits outside of %s [%d]", tcpFlagsValue, value, tcpFlagsMask, mask)
 	}
 
 	return &vppacl.ACL_Rule_IpRule_Tcp{
-		DestinationPortRange: &vppacl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(lowerPort),
-			UpperPort: uint32(upperPort),
-		},
-		SourcePortRange: &vppacl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(0),
-			UpperPort: uint32(65535),
-		},
-		TcpFlagsMask:  0,
-		TcpFlagsValue: 0,
+		DestinationPortRange: dstRange,
+		SourcePortRange:      srcRange,
+		TcpFlagsMask:         mask,
+		TcpFlagsValue:        value,
 	}, nil
 }
 
 func getUDP(parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Udp, error) {
-	lowerPort, lpFound, lpErr := getPort(udpLowPort, parsed)
-	if !lpFound {
+	if !hasAnyKey(parsed, udpKeys) {
 		return nil, nil
-	} else if lpErr != nil {
-		return nil, lpErr
 	}
-
-	upperPort, upFound, upErr := getPort(udpUpPort, parsed)
-	if !upFound {
-		return nil, nil
-	} else if upErr != nil {
-		return nil, lpErr
+	dstRange, err := getPortRange(udpLowPort, udpUpPort, parsed)
+	if err != nil {
+		return nil, err
+	}
+	srcRange, err := getPortRange(udpSrcLowPort, udpSrcUpPort, parsed)
+	if err != nil {
+		return nil, err
 	}
 
 	return &vppacl.ACL_Rule_IpRule_Udp{
-		DestinationPortRange: &vppacl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(lowerPort),
-			UpperPort: uint32(upperPort),
-		},
-		SourcePortRange: &vppacl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(0),
-			UpperPort: uint32(65535),
-		},
+		DestinationPortRange: dstRange,
+		SourcePortRange:      srcRange,
 	}, nil
 }
 
 func getIPRule(parsed map[string]string) (*vppacl.ACL_Rule_IpRule, error) {
+	var l4 int
+	for _, keys := range [][]string{icmpKeys, tcpKeys, udpKeys} {
+		if hasAnyKey(parsed, keys) {
+			l4++
+		}
+	}
+	if l4 > 1 {
+		return nil, errors.New("icmp, tcp and udp keys are mutually exclusive")
+	}
+	if _, ok := parsed[protocol]; ok && l4 > 0 {
+		return nil, errors.Errorf("'%s' can't be used with the icmp, tcp and udp keys", protocol)
+	}
+
 	ip, err := getIP(parsed)
 	if err != nil {
 		return nil, err
@@ -220,7 +388,57 @@ func getIPRule(parsed map[string]string) (*vppacl.ACL_Rule_IpRule, error) {
 	}, nil
 }
 
+func getMacIPRule(parsed map[string]string) (*vppacl.ACL_Rule_MacIpRule, error) {
+	for key := range parsed {
+		if !keySet(macIPKeys)[key] {
+			return nil, errors.Errorf("'%s' is not allowed in a MAC-IP rule", key)
+		}
+	}
+	if strings.EqualFold(parsed[action], vppacl.ACL_Rule_REFLECT.String()) {
+		return nil, errors.New("MAC-IP rule can't have 'action' REFLECT")
+	}
+	mac, err := net.ParseMAC(parsed[srcMac])
+	if err != nil {
+		return nil, errors.Errorf("failed parsing %s [%v] with: %v", srcMac, parsed[srcMac], err)
+	}
+	mask := defaultMacMask
+	if value, ok := parsed[srcMacMask]; ok {
+		if _, err = net.ParseMAC(value); err != nil {
+			return nil, errors.Errorf("failed parsing %s [%v] with: %v", srcMacMask, value, err)
+		}
+		mask = value
+	}
+	rv := &vppacl.ACL_Rule_MacIpRule{
+		SourceAddress:        net.IPv4zero.String(),
+		SourceMacAddress:     mac.String(),
+		SourceMacAddressMask: mask,
+	}
+	if value, ok := parsed[srcNet]; ok {
+		ip, ipNet, cidrErr := net.ParseCIDR(value)
+		if cidrErr != nil {
+			return nil, errors.Errorf("srcnet is not a valid CIDR [%v]. Failed with: %v", value, cidrErr)
+		}
+		prefix, _ := ipNet.Mask.Size()
+		rv.SourceAddress = ip.String()
+		rv.SourceAddressPrefix = uint32(prefix)
+	}
+	return rv, nil
+}
+
 func getMatch(parsed map[string]string) (*vppacl.ACL_Rule, error) {
+	if _, ok := parsed[srcMac]; ok {
+		macIPRule, err := getMacIPRule(parsed)
+		if err != nil {
+			return nil, err
+		}
+		return &vppacl.ACL_Rule{
+			MacipRule: macIPRule,
+		}, nil
+	}
+	if _, ok := parsed[srcMacMask]; ok {
+		return nil, errors.Errorf("'%s' requires '%s'", srcMacMask, srcMac)
+	}
+
 	ipRule, err := getIPRule(parsed)
 	if err != nil {
 		return nil, err
@@ -232,21 +450,47 @@ func getMatch(parsed map[string]string) (*vppacl.ACL_Rule, error) {
 	}, nil
 }
 
-// parseKVStringToMap parses the input string
-func parseKVStringToMap(input, sep, kvsep string) map[string]string {
+func hasAnyKey(parsed map[string]string, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := parsed[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func keySet(keys []string) map[string]bool {
+	rv := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		rv[key] = true
+	}
+	return rv
+}
+
+// parseKVStringToMap parses the input string, empty pairs are skipped
+func parseKVStringToMap(input, sep, kvsep string) (map[string]string, error) {
 	result := map[string]string{}
 	pairs := strings.Split(input, sep)
 	for _, pair := range pairs {
-		k, v := parseKV(pair, kvsep)
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, err := parseKV(pair, kvsep)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := result[k]; ok {
+			return nil, errors.Errorf("duplicate key '%s'", k)
+		}
 		result[k] = v
 	}
-	return result
+	return result, nil
 }
 
-func parseKV(kv, kvsep string) (key, value string) {
+func parseKV(kv, kvsep string) (key, value string, err error) {
 	keyValue := strings.Split(kv, kvsep)
-	if len(keyValue) != 2 {
-		keyValue = []string{"", ""}
+	if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) == "" {
+		return "", "", errors.Errorf("'%s' is not a key%svalue pair", strings.TrimSpace(kv), kvsep)
 	}
-	return strings.Trim(keyValue[0], " "), strings.Trim(keyValue[1], " ")
+	return strings.Trim(keyValue[0], " "), strings.Trim(keyValue[1], " "), nil
 }
@@ -0,0 +1,164 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/acl"
+)
+
+func allPorts() *vppacl.ACL_Rule_IpRule_PortRange {
+	return &vppacl.ACL_Rule_IpRule_PortRange{LowerPort: 0, UpperPort: 65535}
+}
+
+func TestMapToRulesOrder(t *testing.T) {
+	rules, err := acl.MapToRules(map[string]string{
+		"b":  "action=deny,dstnet=10.0.0.2/32",
+		"10": "action=deny,dstnet=10.0.0.10/32",
+		"a":  "action=deny,dstnet=10.0.0.1/32",
+		"2":  "action=deny,dstnet=10.0.0.20/32",
+	})
+	require.NoError(t, err)
+	var dstNets []string
+	for _, rule := range rules {
+		dstNets = append(dstNets, rule.GetIpRule().GetIp().GetDestinationNetwork())
+	}
+	assert.Equal(t, []string{"10.0.0.20/32", "10.0.0.10/32", "10.0.0.1/32", "10.0.0.2/32"}, dstNets)
+}
+
+func TestMapToRulesTCP(t *testing.T) {
+	rules, err := acl.MapToRules(map[string]string{
+		"tcp": "action=permit,srcnet=10.0.0.0/8,tcplowport=80,tcpupport=8080,tcpsrclowport=1024," +
+			"tcpflagsmask=18,tcpflagsvalue=2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*vppacl.ACL_Rule{{
+		Action: vppacl.ACL_Rule_PERMIT,
+		IpRule: &vppacl.ACL_Rule_IpRule{
+			Ip: &vppacl.ACL_Rule_IpRule_Ip{SourceNetwork: "10.0.0.0/8"},
+			Tcp: &vppacl.ACL_Rule_IpRule_Tcp{
+				DestinationPortRange: &vppacl.ACL_Rule_IpRule_PortRange{LowerPort: 80, UpperPort: 8080},
+				SourcePortRange:      &vppacl.ACL_Rule_IpRule_PortRange{LowerPort: 1024, UpperPort: 65535},
+				TcpFlagsMask:         18,
+				TcpFlagsValue:        2,
+			},
+		},
+	}}, rules)
+}
+
+func TestMapToRulesUDPAndProtocol(t *testing.T) {
+	rules, err := acl.MapToRules(map[string]string{
+		"1": "action=reflect,udpsrclowport=53,udpsrcupport=53",
+		"2": "action=deny,proto=47",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*vppacl.ACL_Rule{
+		{
+			Action: vppacl.ACL_Rule_REFLECT,
+			IpRule: &vppacl.ACL_Rule_IpRule{
+				Udp: &vppacl.ACL_Rule_IpRule_Udp{
+					DestinationPortRange: allPorts(),
+					SourcePortRange:      &vppacl.ACL_Rule_IpRule_PortRange{LowerPort: 53, UpperPort: 53},
+				},
+			},
+		},
+		{
+			Action: vppacl.ACL_Rule_DENY,
+			IpRule: &vppacl.ACL_Rule_IpRule{
+				Ip: &vppacl.ACL_Rule_IpRule_Ip{Protocol: 47},
+			},
+		},
+	}, rules)
+}
+
+func TestMapToRulesICMP(t *testing.T) {
+	rules, err := acl.MapToRules(map[string]string{
+		"1": "action=permit,icmptype=8",
+		"2": "action=permit,icmpv6=true,icmptype=133-137,icmpcode=0",
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, &vppacl.ACL_Rule_IpRule_Icmp{
+		IcmpCodeRange: &vppacl.ACL_Rule_IpRule_Icmp_Range{First: 0, Last: 65535},
+		IcmpTypeRange: &vppacl.ACL_Rule_IpRule_Icmp_Range{First: 8, Last: 8},
+	}, rules[0].GetIpRule().GetIcmp())
+	assert.Equal(t, &vppacl.ACL_Rule_IpRule_Icmp{
+		Icmpv6:        true,
+		IcmpCodeRange: &vppacl.ACL_Rule_IpRule_Icmp_Range{First: 0, Last: 0},
+		IcmpTypeRange: &vppacl.ACL_Rule_IpRule_Icmp_Range{First: 133, Last: 137},
+	}, rules[1].GetIpRule().GetIcmp())
+}
+
+func TestMapToRulesMacIP(t *testing.T) {
+	rules, err := acl.MapToRules(map[string]string{
+		"mac": "action=permit,srcnet=10.0.0.1/32,srcmac=0A:1B:3C:4D:5E:6F",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*vppacl.ACL_Rule{{
+		Action: vppacl.ACL_Rule_PERMIT,
+		MacipRule: &vppacl.ACL_Rule_MacIpRule{
+			SourceAddress:        "10.0.0.1",
+			SourceAddressPrefix:  32,
+			SourceMacAddress:     "0a:1b:3c:4d:5e:6f",
+			SourceMacAddressMask: "ff:ff:ff:ff:ff:ff",
+		},
+	}}, rules)
+}
+
+func TestMapToRulesErrors(t *testing.T) {
+	for rule, message := range map[string]string{
+		"dstnet=10.0.0.0/8":                                        "'action'",
+		"action=drop":                                              "'action'",
+		"action=permit,dstnett=10.0.0.0/8":                         "unknown key 'dstnett'",
+		"action=permit,dstnet":                                     "not a key=value pair",
+		"action=permit,action=deny":                                "duplicate key 'action'",
+		"action=permit,tcplowport=80,tcpupport=65536":              "tcpupport",
+		"action=permit,tcplowport=8080,tcpupport=80":               "tcplowport [8080] is greater than tcpupport [80]",
+		"action=permit,tcpflagsmask=2,tcpflagsvalue=3":             "outside of tcpflagsmask",
+		"action=permit,tcplowport=80,udplowport=53":                "mutually exclusive",
+		"action=permit,proto=6,tcplowport=80":                      "'proto' can't be used",
+		"action=permit,icmptype=256":                               "icmptype",
+		"action=permit,icmptype=10-8":                              "empty range",
+		"action=permit,srcmac=0a:1b:3c:4d:5e:6f,dstnet=10.0.0.0/8": "'dstnet' is not allowed in a MAC-IP rule",
+		"action=reflect,srcmac=0a:1b:3c:4d:5e:6f":                  "REFLECT",
+		"action=permit,srcmacmask=ff:ff:ff:ff:ff:ff":               "requires 'srcmac'",
+	} {
+		_, err := acl.MapToRules(map[string]string{"rule-key": rule})
+		require.Error(t, err, rule)
+		assert.Contains(t, err.Error(), "rule-key", rule)
+		assert.Contains(t, err.Error(), message, rule)
+	}
+}
+
+func TestRuleListErrors(t *testing.T) {
+	_, err := acl.MapToRules(map[string]string{
+		"ip":  "action=permit,dstnet=10.0.0.0/8",
+		"mac": "action=permit,srcmac=0a:1b:3c:4d:5e:6f",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't be mixed")
+
+	_, err = acl.ParseRules([]byte("ingress:\n  mac: action=permit,srcmac=0a:1b:3c:4d:5e:6f\n" +
+		"egress:\n  mac: action=permit,srcmac=0a:1b:3c:4d:5e:6f\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "egress")
+}
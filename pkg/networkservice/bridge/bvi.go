@@ -0,0 +1,108 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bridge
+
+import (
+	"net"
+	"sort"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	l2 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l2"
+)
+
+const bviNamePrefix = "bvi-"
+
+func bviName(bridgeName string) string {
+	return bviNamePrefix + bridgeName
+}
+
+// bviInterface - returns the loopback BVI with the static addresses followed by the addresses of the connections
+func bviInterface(bridgeName string, o *options, addresses []string) *vppinterfaces.Interface {
+	sort.Strings(addresses)
+	seen := make(map[string]bool)
+	var ipAddresses []string
+	for _, addr := range append(append([]string{}, o.bviAddresses...), addresses...) {
+		if !seen[addr] {
+			seen[addr] = true
+			ipAddresses = append(ipAddresses, addr)
+		}
+	}
+	return &vppinterfaces.Interface{
+		Name:        bviName(bridgeName),
+		Type:        vppinterfaces.Interface_SOFTWARE_LOOPBACK,
+		Enabled:     true,
+		IpAddresses: ipAddresses,
+		Vrf:         o.bviVrf,
+	}
+}
+
+// newMember - returns the member for the interface in the split-horizon group of the connection with the endpoint
+// side address of the connection and the ARP termination entry of its peer
+func newMember(ifaceName string, o *options, conn *networkservice.Connection) (*bridgeMember, error) {
+	group, err := splitHorizonGroup(o, conn)
+	if err != nil {
+		return nil, err
+	}
+	member := &bridgeMember{
+		iface: &l2.BridgeDomain_Interface{
+			Name:                    ifaceName,
+			BridgedVirtualInterface: false,
+			SplitHorizonGroup:       group,
+		},
+	}
+	ipContext := conn.GetContext().GetIpContext()
+	if o.bvi && ipContext.GetDstIpAddr() != "" {
+		member.addresses = []string{ipContext.GetDstIpAddr()}
+	}
+	peerMac := conn.GetContext().GetEthernetContext().GetSrcMac()
+	if ip := extractCleanIPAddress(ipContext.GetSrcIpAddr()); ip != "" && peerMac != "" {
+		member.arps = []*l2.BridgeDomain_ArpTerminationEntry{{
+			IpAddress:   ip,
+			PhysAddress: peerMac,
+		}}
+	}
+	return member, nil
+}
+
+func extractCleanIPAddress(addr string) string {
+	if ip, _, err := net.ParseCIDR(addr); err == nil {
+		return ip.String()
+	}
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// putInterface - puts the interface into vppConfig replacing the one with the same name. A new interface goes before
+// the last one, so the elements using the last interface keep using the one of the connection.
+func putInterface(vppConfig *vpp.ConfigData, iface *vppinterfaces.Interface) {
+	for i, existing := range vppConfig.GetInterfaces() {
+		if existing.GetName() == iface.GetName() {
+			vppConfig.Interfaces[i] = iface
+			return
+		}
+	}
+	index := len(vppConfig.GetInterfaces()) - 1
+	if index < 0 {
+		vppConfig.Interfaces = append(vppConfig.Interfaces, iface)
+		return
+	}
+	vppConfig.Interfaces = append(vppConfig.Interfaces[:index], append([]*vppinterfaces.Interface{iface}, vppConfig.Interfaces[index:]...)...)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bridge
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	l2 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l2"
+	vppl3 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l3"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+// bridgeDomains - a bridge domain is shared by all the connections plugged into it, so the bridge domains of the
+// process are kept here and the whole bridge domain with all its members is put into the vppagent config of every
+// Request and Close when it is committed
+type bridgeDomains struct {
+	domains map[string]*bridgeDomain
+	mu      sync.Mutex
+}
+
+type bridgeDomain struct {
+	options *options
+	// members - members by the connection id
+	members map[string]*bridgeMember
+}
+
+// bridgeMember - the interface of a connection with its endpoint side addresses for the BVI and the ARP termination
+// entries of its peer
+type bridgeMember struct {
+	iface     *l2.BridgeDomain_Interface
+	addresses []string
+	arps      []*l2.BridgeDomain_ArpTerminationEntry
+}
+
+var globalBridgeDomains = &bridgeDomains{
+	domains: make(map[string]*bridgeDomain),
+}
+
+// add - adds the member of the connection to the bridge domain, the bridge domain is committed along with ctx.
+// Returns false for a new member. The servers sharing the bridge domain must create it with the same options.
+func (b *bridgeDomains) add(ctx context.Context, name string, o *options, connID string, member *bridgeMember) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	d, ok := b.domains[name]
+	if !ok {
+		d = &bridgeDomain{
+			options: o,
+			members: make(map[string]*bridgeMember),
+		}
+		b.domains[name] = d
+	}
+	if !d.options.sameDomain(o) {
+		return false, errors.Errorf("bridge domain %s already exists with other options", name)
+	}
+	_, existed := d.members[connID]
+	d.members[connID] = member
+	vppagent.OnCommit(ctx, b.commit(name, o))
+	return existed, nil
+}
+
+// remove - removes the member of the connection from the bridge domain, the bridge domain without the member is
+// committed along with ctx, it is deleted with the last member
+func (b *bridgeDomains) remove(ctx context.Context, name, connID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	d, ok := b.domains[name]
+	if !ok {
+		return
+	}
+	if _, isMember := d.members[connID]; !isMember {
+		return
+	}
+	delete(d.members, connID)
+	if len(d.members) == 0 {
+		delete(b.domains, name)
+	}
+	vppagent.OnCommit(ctx, b.commit(name, d.options))
+}
+
+// commit - returns the CommitFunc putting the current bridge domain into the update config, the deleted bridge
+// domain is put into the remove config with its BVI
+func (b *bridgeDomains) commit(name string, o *options) vppagent.CommitFunc {
+	return func(configs *vppagent.CommitConfigs) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if d, ok := b.domains[name]; ok {
+			d.putConfig(configs.Update().GetVppConfig(), name)
+			return
+		}
+		vppConfig := configs.Remove().GetVppConfig()
+		if o.bvi {
+			putInterface(vppConfig, &vppinterfaces.Interface{Name: bviName(name)})
+		}
+		putBridgeDomain(vppConfig, &l2.BridgeDomain{Name: name})
+	}
+}
+
+// putConfig - puts the bridge domain and its BVI into vppConfig
+func (d *bridgeDomain) putConfig(vppConfig *vpp.ConfigData, name string) {
+	bd := &l2.BridgeDomain{
+		Name:                name,
+		Flood:               d.options.flood,
+		UnknownUnicastFlood: d.options.unknownUnicastFlood,
+		Forward:             d.options.forward,
+		Learn:               d.options.learn,
+		ArpTermination:      d.options.arpTermination,
+		MacAge:              d.options.macAge,
+	}
+	var addresses []string
+	for _, member := range d.members {
+		bd.Interfaces = append(bd.Interfaces, member.iface)
+		bd.ArpTerminationTable = append(bd.ArpTerminationTable, member.arps...)
+		addresses = append(addresses, member.addresses...)
+	}
+	sort.Slice(bd.Interfaces, func(i, j int) bool {
+		return bd.Interfaces[i].GetName() < bd.Interfaces[j].GetName()
+	})
+	sort.Slice(bd.ArpTerminationTable, func(i, j int) bool {
+		return bd.ArpTerminationTable[i].GetIpAddress() < bd.ArpTerminationTable[j].GetIpAddress()
+	})
+	if d.options.bvi {
+		bd.Interfaces = append(bd.Interfaces, &l2.BridgeDomain_Interface{
+			Name:                    bviName(name),
+			BridgedVirtualInterface: true,
+		})
+		putInterface(vppConfig, bviInterface(name, d.options, addresses))
+		if d.options.bviVrf != 0 {
+			putVrf(vppConfig, &vppl3.VrfTable{Id: d.options.bviVrf, Protocol: vppl3.VrfTable_IPV4, Label: bviName(name)})
+			putVrf(vppConfig, &vppl3.VrfTable{Id: d.options.bviVrf, Protocol: vppl3.VrfTable_IPV6, Label: bviName(name)})
+		}
+	}
+	putBridgeDomain(vppConfig, bd)
+}
+
+// putVrf - puts the VRF table into vppConfig unless it is already there
+func putVrf(vppConfig *vpp.ConfigData, vrf *vppl3.VrfTable) {
+	for _, existing := range vppConfig.GetVrfs() {
+		if existing.GetId() == vrf.GetId() && existing.GetProtocol() == vrf.GetProtocol() {
+			return
+		}
+	}
+	vppConfig.Vrfs = append(vppConfig.Vrfs, vrf)
+}
+
+// putBridgeDomain - puts the bridge domain into vppConfig replacing the one with the same name
+func putBridgeDomain(vppConfig *vpp.ConfigData, bd *l2.BridgeDomain) {
+	for i, d := range vppConfig.GetBridgeDomains() {
+		if d.GetName() == bd.GetName() {
+			vppConfig.BridgeDomains[i] = bd
+			return
+		}
+	}
+	vppConfig.BridgeDomains = append(vppConfig.BridgeDomains, bd)
+}
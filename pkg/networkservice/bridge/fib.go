@@ -0,0 +1,79 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bridge
+
+import (
+	"net"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	l2 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l2"
+)
+
+// maxSplitHorizonGroup - vpp split-horizon groups are 8-bit
+const maxSplitHorizonGroup = 255
+
+// SplitHorizonGroupFunc - returns the split-horizon group of the connection member, the members of the same non-zero
+// group can't forward to each other, 0 is no group
+type SplitHorizonGroupFunc func(conn *networkservice.Connection) uint32
+
+// SingleSplitHorizonGroup - puts all the connections into the group, so the clients talk only to the BVI and to the
+// members with no group
+func SingleSplitHorizonGroup(group uint32) SplitHorizonGroupFunc {
+	return func(*networkservice.Connection) uint32 {
+		return group
+	}
+}
+
+// SplitHorizonGroupByLabel - selects the group by the value of the connection label, the connections with the values
+// missing in groups get defaultGroup
+func SplitHorizonGroupByLabel(label string, groups map[string]uint32, defaultGroup uint32) SplitHorizonGroupFunc {
+	return func(conn *networkservice.Connection) uint32 {
+		if group, ok := groups[conn.GetLabels()[label]]; ok {
+			return group
+		}
+		return defaultGroup
+	}
+}
+
+// splitHorizonGroup - returns the split-horizon group of the connection
+func splitHorizonGroup(o *options, conn *networkservice.Connection) (uint32, error) {
+	if o.splitHorizonGroup == nil {
+		return 0, nil
+	}
+	group := o.splitHorizonGroup(conn)
+	if group > maxSplitHorizonGroup {
+		return 0, errors.Errorf("split-horizon group %d of connection %s exceeds %d", group, conn.GetId(), maxSplitHorizonGroup)
+	}
+	return group, nil
+}
+
+// appendFIB - appends the static FIB entry forwarding the MAC of the peer of the connection to the interface
+func appendFIB(vppConfig *vpp.ConfigData, bridgeName, ifaceName string, conn *networkservice.Connection) {
+	mac, err := net.ParseMAC(conn.GetContext().GetEthernetContext().GetSrcMac())
+	if err != nil || ifaceName == "" {
+		return
+	}
+	vppConfig.Fibs = append(vppConfig.Fibs, &l2.FIBEntry{
+		PhysAddress:       mac.String(),
+		BridgeDomain:      bridgeName,
+		Action:            l2.FIBEntry_FORWARD,
+		OutgoingInterface: ifaceName,
+		StaticConfig:      true,
+	})
+}
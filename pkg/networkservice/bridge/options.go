@@ -0,0 +1,141 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bridge
+
+// Option - option for NewServer
+type Option func(o *options)
+
+type options struct {
+	flood               bool
+	unknownUnicastFlood bool
+	forward             bool
+	learn               bool
+	arpTermination      bool
+	macAge              uint32
+	bvi                 bool
+	bviAddresses        []string
+	bviVrf              uint32
+	staticFIB           bool
+	splitHorizonGroup   SplitHorizonGroupFunc
+}
+
+// WithFlood - enables flooding of the broadcast and multicast traffic, disabled by default
+func WithFlood(flood bool) Option {
+	return func(o *options) {
+		o.flood = flood
+	}
+}
+
+// WithUnknownUnicastFlood - enables flooding of the unicast traffic to unknown MACs, disabled by default
+func WithUnknownUnicastFlood(flood bool) Option {
+	return func(o *options) {
+		o.unknownUnicastFlood = flood
+	}
+}
+
+// WithForward - enables forwarding by the learned MACs, enabled by default
+func WithForward(forward bool) Option {
+	return func(o *options) {
+		o.forward = forward
+	}
+}
+
+// WithLearn - enables MAC learning, enabled by default
+func WithLearn(learn bool) Option {
+	return func(o *options) {
+		o.learn = learn
+	}
+}
+
+// WithARPTermination - enables answering the ARP requests from the ARP termination table, disabled by default. The
+//                      table has the static entries of the peers of the connections.
+func WithARPTermination(arpTermination bool) Option {
+	return func(o *options) {
+		o.arpTermination = arpTermination
+	}
+}
+
+// WithMacAge - sets the age of the learned MACs in minutes, 0 (default) disables aging
+func WithMacAge(minutes uint32) Option {
+	return func(o *options) {
+		o.macAge = minutes
+	}
+}
+
+// WithBVI - creates a loopback BVI for the bridge domain with the addresses and the endpoint side addresses assigned to
+//           the connections by the IPAM, so the bridge can be routed
+func WithBVI(addresses ...string) Option {
+	return func(o *options) {
+		o.bvi = true
+		o.bviAddresses = addresses
+	}
+}
+
+// WithBVIVrf - attaches the BVI to the VRF, the VRF table is created with the bridge domain and it is left in place
+//              when the bridge domain is deleted, since other elements may use it
+func WithBVIVrf(vrf uint32) Option {
+	return func(o *options) {
+		o.bviVrf = vrf
+	}
+}
+
+// WithStaticFIB - adds static FIB entries forwarding the MACs of the peers of the connections to their interfaces
+func WithStaticFIB() Option {
+	return func(o *options) {
+		o.staticFIB = true
+	}
+}
+
+// WithSplitHorizonGroups - assigns the members to the split-horizon groups selected by the func, so the clients of the
+//                          same group can't talk to each other
+func WithSplitHorizonGroups(splitHorizonGroup SplitHorizonGroupFunc) Option {
+	return func(o *options) {
+		o.splitHorizonGroup = splitHorizonGroup
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		forward: true,
+		learn:   true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// sameDomain - returns true if the options create the same bridge domain, the static FIB and the split-horizon groups
+// are per server
+func (o *options) sameDomain(other *options) bool {
+	if len(o.bviAddresses) != len(other.bviAddresses) {
+		return false
+	}
+	for i := range o.bviAddresses {
+		if o.bviAddresses[i] != other.bviAddresses[i] {
+			return false
+		}
+	}
+	return o.flood == other.flood &&
+		o.unknownUnicastFlood == other.unknownUnicastFlood &&
+		o.forward == other.forward &&
+		o.learn == other.learn &&
+		o.arpTermination == other.arpTermination &&
+		o.macAge == other.macAge &&
+		o.bvi == other.bvi &&
+		o.bviVrf == other.bviVrf
+}
@@ -22,8 +22,6 @@ import (
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
-	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
-	l2 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l2"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
@@ -31,41 +29,54 @@ import (
 )
 
 type bridgeServer struct {
-	name string
+	name    string
+	options *options
 }
 
-// NewServer creates a NetworkServiceServer that will plug an incoming vWire into a bridge named 'name'
-func NewServer(name string) networkservice.NetworkServiceServer {
-	return &bridgeServer{name: name}
+// NewServer creates a NetworkServiceServer that will plug an incoming vWire into a bridge named 'name', the bridge
+// domain is created with the first vWire and deleted with the last one. The servers plugging into the same bridge
+// must have the same bridge domain options.
+func NewServer(name string, opts ...Option) networkservice.NetworkServiceServer {
+	return &bridgeServer{
+		name:    name,
+		options: newOptions(opts...),
+	}
 }
 
 func (b *bridgeServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
 	conf := vppagent.Config(ctx)
-	b.insertInterfaceIntoBridge(conf)
-	return next.Server(ctx).Request(ctx, request)
+	ifaces := conf.GetVppConfig().GetInterfaces()
+	if len(ifaces) == 0 {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	ifaceName := ifaces[len(ifaces)-1].GetName()
+	member, err := newMember(ifaceName, b.options, request.GetConnection())
+	if err != nil {
+		return nil, err
+	}
+	connID := request.GetConnection().GetId()
+	existed, err := globalBridgeDomains.add(ctx, b.name, b.options, connID, member)
+	if err != nil {
+		return nil, err
+	}
+	if b.options.staticFIB {
+		appendFIB(conf.GetVppConfig(), b.name, ifaceName, request.GetConnection())
+	}
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		if !existed {
+			globalBridgeDomains.remove(ctx, b.name, connID)
+		}
+		return nil, err
+	}
+	return conn, nil
 }
 
 func (b *bridgeServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
 	conf := vppagent.Config(ctx)
-	b.insertInterfaceIntoBridge(conf)
-	return next.Server(ctx).Close(ctx, conn)
-}
-
-func (b *bridgeServer) insertInterfaceIntoBridge(conf *configurator.Config) {
-	if len(conf.GetVppConfig().GetInterfaces()) > 0 {
-		conf.GetVppConfig().BridgeDomains = append(conf.GetVppConfig().BridgeDomains, &l2.BridgeDomain{
-			Name:                b.name,
-			Flood:               false,
-			UnknownUnicastFlood: false,
-			Forward:             true,
-			Learn:               true,
-			ArpTermination:      false,
-			Interfaces: []*l2.BridgeDomain_Interface{
-				{
-					Name:                    conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].GetName(),
-					BridgedVirtualInterface: false,
-				},
-			},
-		})
+	if ifaces := conf.GetVppConfig().GetInterfaces(); b.options.staticFIB && len(ifaces) > 0 {
+		appendFIB(conf.GetVppConfig(), b.name, ifaces[len(ifaces)-1].GetName(), conn)
 	}
+	globalBridgeDomains.remove(ctx, b.name, conn.GetId())
+	return next.Server(ctx).Close(ctx, conn)
 }
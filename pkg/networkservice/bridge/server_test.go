@@ -0,0 +1,317 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bridge_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	memif_mechanisms "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	l2 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l2"
+	vppl3 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l3"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/bridge"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func request(id string) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: id,
+			Mechanism: &networkservice.Mechanism{
+				Cls:        cls.LOCAL,
+				Type:       memif_mechanisms.MECHANISM,
+				Parameters: map[string]string{memif_mechanisms.SocketFilename: "socketfilename"},
+			},
+			Context: &networkservice.ConnectionContext{},
+		},
+	}
+}
+
+func newServer(name string, opts ...bridge.Option) networkservice.NetworkServiceServer {
+	logrus.SetOutput(ioutil.Discard)
+	return chain.NewNetworkServiceServer(
+		memif.NewServer("BaseDir"),
+		bridge.NewServer(name, opts...),
+	)
+}
+
+// commit - returns the configs committed for ctx
+func commit(ctx context.Context, t *testing.T, isClose bool) (update, remove *configurator.Config) {
+	require.NoError(t, vppagent.Commit(ctx, isClose, func(u, r *configurator.Config) error {
+		update, remove = u, r
+		return nil
+	}))
+	return update, remove
+}
+
+func members(names ...string) []*l2.BridgeDomain_Interface {
+	var rv []*l2.BridgeDomain_Interface
+	for _, name := range names {
+		rv = append(rv, &l2.BridgeDomain_Interface{Name: name})
+	}
+	return rv
+}
+
+func TestBridgeServerMembers(t *testing.T) {
+	server := newServer("bd-members")
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn1, err := server.Request(ctx, request("1"))
+	require.NoError(t, err)
+	ctx = vppagent.WithConfig(context.Background())
+	conn2, err := server.Request(ctx, request("2"))
+	require.NoError(t, err)
+	update, _ := commit(ctx, t, false)
+	assert.Equal(t, []*l2.BridgeDomain{{
+		Name:       "bd-members",
+		Forward:    true,
+		Learn:      true,
+		Interfaces: members("server-1", "server-2"),
+	}}, update.GetVppConfig().GetBridgeDomains())
+
+	// The bridge domain is still used by the second connection, it is updated without the closed member
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, conn1)
+	require.NoError(t, err)
+	update, remove := commit(ctx, t, true)
+	assert.Empty(t, remove.GetVppConfig().GetBridgeDomains())
+	require.Len(t, update.GetVppConfig().GetBridgeDomains(), 1)
+	assert.Equal(t, members("server-2"), update.GetVppConfig().GetBridgeDomains()[0].GetInterfaces())
+
+	// Refresh of the second connection keeps the members
+	ctx = vppagent.WithConfig(context.Background())
+	conn2, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn2})
+	require.NoError(t, err)
+	update, _ = commit(ctx, t, false)
+	require.Len(t, update.GetVppConfig().GetBridgeDomains(), 1)
+	assert.Equal(t, members("server-2"), update.GetVppConfig().GetBridgeDomains()[0].GetInterfaces())
+
+	// The last connection deletes the bridge domain
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, conn2)
+	require.NoError(t, err)
+	update, remove = commit(ctx, t, true)
+	assert.Nil(t, update)
+	require.Len(t, remove.GetVppConfig().GetBridgeDomains(), 1)
+	assert.Equal(t, "bd-members", remove.GetVppConfig().GetBridgeDomains()[0].GetName())
+
+	// Close of an unknown connection leaves the bridge domains alone
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, conn2)
+	require.NoError(t, err)
+	update, remove = commit(ctx, t, true)
+	assert.Nil(t, update)
+	assert.Empty(t, remove.GetVppConfig().GetBridgeDomains())
+}
+
+func TestBridgeServerCommitsCurrentState(t *testing.T) {
+	server := newServer("bd-current")
+
+	ctx1 := vppagent.WithConfig(context.Background())
+	conn1, err := server.Request(ctx1, request("current-1"))
+	require.NoError(t, err)
+	ctx2 := vppagent.WithConfig(context.Background())
+	conn2, err := server.Request(ctx2, request("current-2"))
+	require.NoError(t, err)
+
+	// The commit of the first Request coming last doesn't send its stale member list
+	update, _ := commit(ctx2, t, false)
+	assert.Equal(t, members("server-current-1", "server-current-2"), update.GetVppConfig().GetBridgeDomains()[0].GetInterfaces())
+	update, _ = commit(ctx1, t, false)
+	assert.Equal(t, members("server-current-1", "server-current-2"), update.GetVppConfig().GetBridgeDomains()[0].GetInterfaces())
+
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn1)
+	require.NoError(t, err)
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn2)
+	require.NoError(t, err)
+}
+
+func TestBridgeServerMismatchedOptions(t *testing.T) {
+	server := newServer("bd-mismatch")
+	conn, err := server.Request(vppagent.WithConfig(context.Background()), request("mismatch-1"))
+	require.NoError(t, err)
+
+	_, err = newServer("bd-mismatch", bridge.WithFlood(true)).Request(vppagent.WithConfig(context.Background()), request("mismatch-2"))
+	require.Error(t, err)
+
+	// The per server options may differ
+	other, err := newServer("bd-mismatch", bridge.WithStaticFIB()).Request(vppagent.WithConfig(context.Background()), request("mismatch-3"))
+	require.NoError(t, err)
+
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn)
+	require.NoError(t, err)
+	_, err = server.Close(vppagent.WithConfig(context.Background()), other)
+	require.NoError(t, err)
+}
+
+func TestBridgeServerOptions(t *testing.T) {
+	server := newServer("bd-options",
+		bridge.WithFlood(true),
+		bridge.WithUnknownUnicastFlood(true),
+		bridge.WithForward(false),
+		bridge.WithLearn(false),
+		bridge.WithARPTermination(true),
+		bridge.WithMacAge(5),
+	)
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, request("options"))
+	require.NoError(t, err)
+	update, _ := commit(ctx, t, false)
+	assert.Equal(t, []*l2.BridgeDomain{{
+		Name:                "bd-options",
+		Flood:               true,
+		UnknownUnicastFlood: true,
+		ArpTermination:      true,
+		MacAge:              5,
+		Interfaces:          members("server-options"),
+	}}, update.GetVppConfig().GetBridgeDomains())
+
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn)
+	require.NoError(t, err)
+}
+
+func TestBridgeServerBVI(t *testing.T) {
+	server := newServer("bd-bvi",
+		bridge.WithARPTermination(true),
+		bridge.WithBVI("10.90.0.1/24"),
+		bridge.WithBVIVrf(7),
+	)
+
+	var conns []*networkservice.Connection
+	var ctx context.Context
+	for i, id := range []string{"bvi-1", "bvi-2"} {
+		req := request(id)
+		req.GetConnection().GetContext().IpContext = &networkservice.IPContext{
+			SrcIpAddr: fmt.Sprintf("10.90.1.%d/32", 2*i+1),
+			DstIpAddr: fmt.Sprintf("10.90.1.%d/32", 2*i+2),
+		}
+		req.GetConnection().GetContext().EthernetContext = &networkservice.EthernetContext{
+			SrcMac: fmt.Sprintf("0a:1b:3c:4d:5e:%02x", i),
+		}
+		ctx = vppagent.WithConfig(context.Background())
+		conn, err := server.Request(ctx, req)
+		require.NoError(t, err)
+		conns = append(conns, conn)
+	}
+
+	update, _ := commit(ctx, t, false)
+	vppConfig := update.GetVppConfig()
+	require.Len(t, vppConfig.GetInterfaces(), 2)
+	// The connection interface stays the last one
+	assert.Equal(t, "server-bvi-2", vppConfig.GetInterfaces()[1].GetName())
+	assert.Equal(t, &vppinterfaces.Interface{
+		Name:        "bvi-bd-bvi",
+		Type:        vppinterfaces.Interface_SOFTWARE_LOOPBACK,
+		Enabled:     true,
+		IpAddresses: []string{"10.90.0.1/24", "10.90.1.2/32", "10.90.1.4/32"},
+		Vrf:         7,
+	}, vppConfig.GetInterfaces()[0])
+	require.Len(t, vppConfig.GetBridgeDomains(), 1)
+	bd := vppConfig.GetBridgeDomains()[0]
+	assert.Equal(t, append(members("server-bvi-1", "server-bvi-2"), &l2.BridgeDomain_Interface{
+		Name:                    "bvi-bd-bvi",
+		BridgedVirtualInterface: true,
+	}), bd.GetInterfaces())
+	assert.Equal(t, []*l2.BridgeDomain_ArpTerminationEntry{
+		{IpAddress: "10.90.1.1", PhysAddress: "0a:1b:3c:4d:5e:00"},
+		{IpAddress: "10.90.1.3", PhysAddress: "0a:1b:3c:4d:5e:01"},
+	}, bd.GetArpTerminationTable())
+	assert.Equal(t, []*vppl3.VrfTable{
+		{Id: 7, Protocol: vppl3.VrfTable_IPV4, Label: "bvi-bd-bvi"},
+		{Id: 7, Protocol: vppl3.VrfTable_IPV6, Label: "bvi-bd-bvi"},
+	}, vppConfig.GetVrfs())
+
+	for _, conn := range conns {
+		ctx = vppagent.WithConfig(context.Background())
+		_, err := server.Close(ctx, conn)
+		require.NoError(t, err)
+	}
+	// The last connection deletes the BVI with the bridge domain
+	_, remove := commit(ctx, t, true)
+	vppConfig = remove.GetVppConfig()
+	require.Len(t, vppConfig.GetInterfaces(), 2)
+	assert.Equal(t, "bvi-bd-bvi", vppConfig.GetInterfaces()[0].GetName())
+	assert.Len(t, vppConfig.GetBridgeDomains(), 1)
+}
+
+func TestBridgeServerStaticFIBAndSplitHorizon(t *testing.T) {
+	server := newServer("bd-shg",
+		bridge.WithStaticFIB(),
+		bridge.WithSplitHorizonGroups(bridge.SplitHorizonGroupByLabel("role", map[string]uint32{"gateway": 0}, 1)),
+	)
+
+	var conns []*networkservice.Connection
+	var ctx context.Context
+	for i, role := range []string{"client", "gateway"} {
+		req := request("shg-" + role)
+		req.GetConnection().Labels = map[string]string{"role": role}
+		req.GetConnection().GetContext().EthernetContext = &networkservice.EthernetContext{
+			SrcMac: fmt.Sprintf("0A:1B:3C:4D:5E:%02X", i),
+		}
+		ctx = vppagent.WithConfig(context.Background())
+		conn, err := server.Request(ctx, req)
+		require.NoError(t, err)
+		conns = append(conns, conn)
+	}
+
+	update, _ := commit(ctx, t, false)
+	vppConfig := update.GetVppConfig()
+	require.Len(t, vppConfig.GetBridgeDomains(), 1)
+	assert.Equal(t, []*l2.BridgeDomain_Interface{
+		{Name: "server-shg-client", SplitHorizonGroup: 1},
+		{Name: "server-shg-gateway"},
+	}, vppConfig.GetBridgeDomains()[0].GetInterfaces())
+	assert.Equal(t, []*l2.FIBEntry{{
+		PhysAddress:       "0a:1b:3c:4d:5e:01",
+		BridgeDomain:      "bd-shg",
+		Action:            l2.FIBEntry_FORWARD,
+		OutgoingInterface: "server-shg-gateway",
+		StaticConfig:      true,
+	}}, vppConfig.GetFibs())
+
+	// Close deletes the FIB entry of the connection only
+	ctx = vppagent.WithConfig(context.Background())
+	_, err := server.Close(ctx, conns[0])
+	require.NoError(t, err)
+	update, remove := commit(ctx, t, true)
+	require.Len(t, remove.GetVppConfig().GetFibs(), 1)
+	assert.Equal(t, "0a:1b:3c:4d:5e:00", remove.GetVppConfig().GetFibs()[0].GetPhysAddress())
+	assert.Empty(t, remove.GetVppConfig().GetBridgeDomains())
+	assert.Empty(t, update.GetVppConfig().GetFibs())
+
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conns[1])
+	require.NoError(t, err)
+}
+
+func TestBridgeServerInvalidSplitHorizonGroup(t *testing.T) {
+	server := newServer("bd-shg-invalid", bridge.WithSplitHorizonGroups(bridge.SingleSplitHorizonGroup(256)))
+	_, err := server.Request(vppagent.WithConfig(context.Background()), request("shg-invalid"))
+	require.Error(t, err)
+}
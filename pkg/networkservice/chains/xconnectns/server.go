@@ -23,6 +23,7 @@ import (
 	"context"
 	"net"
 	"net/url"
+	"time"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/adapters"
 	"github.com/networkservicemesh/sdk/pkg/tools/addressof"
@@ -54,6 +55,14 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/vxlan"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/xconnect/l2xconnect"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifparams"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
+)
+
+const (
+	netNSRefreshInterval = 10 * time.Second
+	netNSMetricsPrefix   = "netns_inode_"
 )
 
 type xconnectNSServer struct {
@@ -71,6 +80,17 @@ type xconnectNSServer struct {
 //             ...clientDialOptions - dialOptions for dialing the NSMgr
 func NewServer(ctx context.Context, name string, authzServer networkservice.NetworkServiceServer, tokenGenerator token.GeneratorFunc, vppagentCC grpc.ClientConnInterface, baseDir string, tunnelIP net.IP, vxlanInitFunc func(conf *configurator.Config) error, clientURL *url.URL, clientDialOptions ...grpc.DialOption) endpoint.Endpoint {
 	rv := &xconnectNSServer{}
+	// Network namespace inodes are resolved from a cache kept fresh in the background
+	inodeResolver := netnsinode.NewResolver()
+	inodeResolver.Watch(ctx, netNSRefreshInterval)
+	// Probe once, on the first Request, which kernel interface implementations vpp is able to use for both the
+	// incoming and outgoing connections, the MTU of the kernel interfaces cross connected to a tunnel leaves room for the
+	// encapsulation in the MTU of the vpp interface having the tunnelIP
+	kernelOptions := []kernel.Option{
+		kernel.WithVPPTapProbe(vppagentCC),
+		kernel.WithUnderlayMTU(ifparams.VPPUnderlayMTU(vppagentCC, tunnelIP)),
+		kernel.WithNetNSResolver(netnsurl.NewResolver(netnsurl.WithInodeResolver(inodeResolver))),
+	}
 	rv.Endpoint = endpoint.NewServer(ctx,
 		name,
 		authzServer,
@@ -80,7 +100,7 @@ func NewServer(ctx context.Context, name string, authzServer networkservice.Netw
 		recvfd.NewServer(),
 		mechanisms.NewServer(map[string]networkservice.NetworkServiceServer{
 			memif.MECHANISM:  memif.NewServer(baseDir),
-			kernel.MECHANISM: kernel.NewServer(),
+			kernel.MECHANISM: kernel.NewServer(kernelOptions...),
 			vxlan.MECHANISM:  vxlan.NewServer(tunnelIP, vxlanInitFunc),
 			srv6.MECHANISM:   srv6.NewServer(),
 		}),
@@ -99,7 +119,7 @@ func NewServer(ctx context.Context, name string, authzServer networkservice.Netw
 				connectioncontextkernel.NewClient(),
 				// Preference ordered list of mechanisms we support for outgoing connections
 				memif.NewClient(baseDir),
-				kernel.NewClient(),
+				kernel.NewClient(kernelOptions...),
 				vxlan.NewClient(tunnelIP, vxlanInitFunc),
 				srv6.NewClient(),
 				recvfd.NewClient()),
@@ -107,7 +127,7 @@ func NewServer(ctx context.Context, name string, authzServer networkservice.Netw
 		),
 		connectioncontextkernel.NewServer(),
 		directmemif.NewServer(),
-		metrics.NewServer(configurator.NewStatsPollerServiceClient(vppagentCC)),
+		metrics.NewServer(configurator.NewStatsPollerServiceClient(vppagentCC), metrics.WithMetricsFunc(netNSMetricsPrefix, inodeResolver.Metrics)),
 		commit.NewServer(vppagentCC),
 	)
 	return rv
@@ -48,27 +48,43 @@ func NewClient(vppagentCC *grpc.ClientConn) networkservice.NetworkServiceClient
 }
 
 func (c *commitClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
-	conf := vppagent.Config(ctx)
 	rv, err := next.Client(ctx).Request(ctx, request, opts...)
 	if err != nil {
 		return nil, err
 	}
-	_, err = c.vppagentClient.Update(ctx, &configurator.UpdateRequest{Update: conf})
+	err = vppagent.Commit(ctx, false, func(update, remove *configurator.Config) error {
+		if _, sendErr := c.vppagentClient.Update(ctx, &configurator.UpdateRequest{Update: update}); sendErr != nil {
+			return errors.Wrapf(sendErr, "error sending config to vppagent %s: ", update)
+		}
+		if remove == nil {
+			return nil
+		}
+		_, sendErr := c.vppagentClient.Delete(ctx, &configurator.DeleteRequest{Delete: remove})
+		return errors.Wrapf(sendErr, "error sending config to vppagent %s: ", remove)
+	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "error sending config to vppagent %s: ", conf)
+		return nil, err
 	}
 	return rv, nil
 }
 
 func (c *commitClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
-	conf := vppagent.Config(ctx)
 	rv, err := next.Client(ctx).Close(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
-	_, err = c.vppagentClient.Delete(ctx, &configurator.DeleteRequest{Delete: conf}, opts...)
+	err = vppagent.Commit(ctx, true, func(update, remove *configurator.Config) error {
+		if _, sendErr := c.vppagentClient.Delete(ctx, &configurator.DeleteRequest{Delete: remove}, opts...); sendErr != nil {
+			return errors.Wrapf(sendErr, "error sending config to vppagent %s: ", remove)
+		}
+		if update == nil {
+			return nil
+		}
+		_, sendErr := c.vppagentClient.Update(ctx, &configurator.UpdateRequest{Update: update}, opts...)
+		return errors.Wrapf(sendErr, "error sending config to vppagent %s: ", update)
+	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "error sending config to vppagent %s: ", conf)
+		return nil, err
 	}
 	return rv, nil
 }
@@ -53,17 +53,33 @@ func (c *commitServer) Request(ctx context.Context, request *networkservice.Netw
 	c.Do(func() {
 		fullResync = true
 	})
-	conf := vppagent.Config(ctx)
-	_, err := c.vppagentClient.Update(ctx, &configurator.UpdateRequest{Update: conf, FullResync: fullResync}, grpc.WaitForReady(true))
+	err := vppagent.Commit(ctx, false, func(update, remove *configurator.Config) error {
+		if _, sendErr := c.vppagentClient.Update(ctx, &configurator.UpdateRequest{Update: update, FullResync: fullResync}, grpc.WaitForReady(true)); sendErr != nil {
+			return errors.Wrapf(sendErr, "error sending config to vppagent %s: ", update)
+		}
+		if remove == nil {
+			return nil
+		}
+		_, sendErr := c.vppagentClient.Delete(ctx, &configurator.DeleteRequest{Delete: remove})
+		return errors.Wrapf(sendErr, "error sending config to vppagent %s: ", remove)
+	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "error sending config to vppagent %s: ", conf)
+		return nil, err
 	}
 	return next.Server(ctx).Request(ctx, request)
 }
 
 func (c *commitServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
-	conf := vppagent.Config(ctx)
-	_, err := c.vppagentClient.Delete(ctx, &configurator.DeleteRequest{Delete: conf})
+	err := vppagent.Commit(ctx, true, func(update, remove *configurator.Config) error {
+		if _, sendErr := c.vppagentClient.Delete(ctx, &configurator.DeleteRequest{Delete: remove}); sendErr != nil {
+			return sendErr
+		}
+		if update == nil {
+			return nil
+		}
+		_, sendErr := c.vppagentClient.Update(ctx, &configurator.UpdateRequest{Update: update})
+		return errors.Wrapf(sendErr, "error sending config to vppagent %s: ", update)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -21,8 +21,12 @@ import (
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/arps"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/macaddress"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/macgen"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/vlan"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/ipaddress"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/routes"
 )
 
 // NewClient creates a NetworkServiceClient chain element to set the ip address on a vpp interface
@@ -49,7 +53,12 @@ import (
 //
 func NewClient() networkservice.NetworkServiceClient {
 	return chain.NewNetworkServiceClient(
+		macgen.NewClient(),
 		ipaddress.NewClient(),
+		routes.NewClient(),
 		macaddress.NewClient(),
+		arps.NewClient(),
+		// Clients apply the config after next in reverse order, so the sub-interface goes first
+		vlan.NewClient(),
 	)
 }
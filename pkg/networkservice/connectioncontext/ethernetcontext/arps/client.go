@@ -0,0 +1,74 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type setVppArpClient struct {
+	*options
+}
+
+// NewClient creates a NetworkServiceClient chain element to set the static ARP/IPv6 neighbor entries for the
+// destination of the connection and for the IpContext neighbors on the *vpp* side of an interface leaving the
+// Endpoint.  With WithProxyARP the vpp interface also answers ARP for the IPv4 DstRoutes.
+func NewClient(options ...Option) networkservice.NetworkServiceClient {
+	return &setVppArpClient{options: newOptions(options...)}
+}
+
+func (s *setVppArpClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	conf := vppagent.Config(ctx)
+	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
+		ifaceName := conf.GetVppConfig().GetInterfaces()[index].GetName()
+		addArps(conf.GetVppConfig(), ifaceName,
+			conn.GetContext().GetIpContext().GetDstIpAddr(),
+			conn.GetContext().GetEthernetContext().GetDstMac(),
+			conn.GetContext().GetIpContext().GetIpNeighbors())
+		if s.proxyARP {
+			globalProxyARPs.set(ctx, ifaceName, conn.GetContext().GetIpContext().GetDstRoutes())
+		}
+	}
+	return conn, nil
+}
+
+func (s *setVppArpClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	e, err := next.Client(ctx).Close(ctx, conn, opts...)
+	conf := vppagent.Config(ctx)
+	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
+		ifaceName := conf.GetVppConfig().GetInterfaces()[index].GetName()
+		addArps(conf.GetVppConfig(), ifaceName,
+			conn.GetContext().GetIpContext().GetDstIpAddr(),
+			conn.GetContext().GetEthernetContext().GetDstMac(),
+			conn.GetContext().GetIpContext().GetIpNeighbors())
+		if s.proxyARP {
+			globalProxyARPs.close(ctx, ifaceName)
+		}
+	}
+	return e, err
+}
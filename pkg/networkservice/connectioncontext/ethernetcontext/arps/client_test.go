@@ -0,0 +1,79 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/checks/checkopts"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vppl3 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l3"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/arps"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func TestVppArpClient(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	client := chain.NewNetworkServiceClient(
+		arps.NewClient(arps.WithProxyARP()),
+		memif.NewClient(BaseDir),
+	)
+	conn := connection("3", &networkservice.IPContext{
+		SrcIpAddr: "fd00::1/127",
+		DstIpAddr: "fd00::2/127",
+		DstRoutes: []*networkservice.Route{{Prefix: "192.168.0.0/16"}},
+	}, &networkservice.EthernetContext{DstMac: "0a:1b:3c:4d:5e:6f"})
+
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := client.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	ifaceName := lastInterfaceName(ctx, t)
+	assert.Equal(t, []*vppl3.ARPEntry{
+		{Interface: ifaceName, IpAddress: "fd00::2", PhysAddress: "0a:1b:3c:4d:5e:6f", Static: true},
+	}, vppagent.Config(ctx).GetVppConfig().GetArps())
+	update, _ := commit(ctx, t, false)
+	assert.Equal(t, &vppl3.ProxyARP{
+		Interfaces: []*vppl3.ProxyARP_Interface{{Name: ifaceName}},
+		Ranges:     []*vppl3.ProxyARP_Range{{FirstIpAddr: "192.168.0.0", LastIpAddr: "192.168.255.255"}},
+	}, update.GetVppConfig().GetProxyArp())
+
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = client.Close(ctx, conn)
+	require.NoError(t, err)
+	assert.Len(t, vppagent.Config(ctx).GetVppConfig().GetArps(), 1)
+	update, remove := commit(ctx, t, true)
+	assert.Nil(t, update)
+	assert.NotNil(t, remove.GetVppConfig().GetProxyArp())
+}
+
+func TestVppArpClientPropagatesOpts(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	client := checkopts.CheckPropogateOptsClient(t, arps.NewClient())
+	conn := connection("4", nil, nil)
+	_, err := client.Request(vppagent.WithConfig(context.Background()), &networkservice.NetworkServiceRequest{Connection: conn})
+	assert.Nil(t, err)
+	_, err = client.Close(vppagent.WithConfig(context.Background()), conn)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppl3 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l3"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+// Option - option for NewClient and NewServer
+type Option func(o *options)
+
+type options struct {
+	proxyARP bool
+}
+
+// WithProxyARP - enables proxy-ARP on the vpp interface for the IPv4 routes the peer sends through the connection,
+//                so the peer may use link scoped routes without a gateway
+func WithProxyARP() Option {
+	return func(o *options) {
+		o.proxyARP = true
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// addArps - adds the static ARP/IPv6 neighbor entries for the peer of the interface
+func addArps(vppConfig *vpp.ConfigData, ifaceName, peerIP, peerMac string, neighbors []*networkservice.IpNeighbor) {
+	if peerMac != "" {
+		if ip := extractCleanIPAddress(peerIP); ip != "" {
+			vppConfig.Arps = append(vppConfig.Arps, arpEntry(ifaceName, ip, peerMac))
+		}
+	}
+	for _, neighbor := range neighbors {
+		if neighbor.GetHardwareAddress() == "" {
+			continue
+		}
+		if ip := extractCleanIPAddress(neighbor.GetIp()); ip != "" {
+			vppConfig.Arps = append(vppConfig.Arps, arpEntry(ifaceName, ip, neighbor.GetHardwareAddress()))
+		}
+	}
+}
+
+func arpEntry(ifaceName, ip, mac string) *vppl3.ARPEntry {
+	return &vppl3.ARPEntry{
+		Interface:   ifaceName,
+		IpAddress:   ip,
+		PhysAddress: mac,
+		Static:      true,
+	}
+}
+
+func extractCleanIPAddress(addr string) string {
+	if ip, _, err := net.ParseCIDR(addr); err == nil {
+		return ip.String()
+	}
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// proxyARPs - vpp has a single global proxy-ARP config, so the ranges of all the interfaces of the process are kept
+// here and the whole config is put into the vppagent config of every Request and Close when it is committed
+type proxyARPs struct {
+	ranges map[string][]*vppl3.ProxyARP_Range
+	mu     sync.Mutex
+}
+
+var globalProxyARPs = &proxyARPs{
+	ranges: make(map[string][]*vppl3.ProxyARP_Range),
+}
+
+// set - sets the proxy-ARP ranges of the interface for the IPv4 routes, no routes remove the interface from the
+// proxy-ARP.  The global config is committed along with ctx if the interface is or was in it.
+func (p *proxyARPs) set(ctx context.Context, ifaceName string, routes []*networkservice.Route) {
+	var ranges []*vppl3.ProxyARP_Range
+	for _, route := range routes {
+		if r := proxyARPRange(route.GetPrefix()); r != nil {
+			ranges = append(ranges, r)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, existed := p.ranges[ifaceName]
+	if len(ranges) == 0 {
+		delete(p.ranges, ifaceName)
+	} else {
+		p.ranges[ifaceName] = ranges
+	}
+	if existed || len(ranges) > 0 {
+		vppagent.OnCommit(ctx, p.commit)
+	}
+}
+
+// close - removes the interface from the proxy-ARP, the global config without the interface is committed along
+// with ctx
+func (p *proxyARPs) close(ctx context.Context, ifaceName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.ranges[ifaceName]; !ok {
+		return
+	}
+	delete(p.ranges, ifaceName)
+	vppagent.OnCommit(ctx, p.commit)
+}
+
+// commit - puts the current global config into the update config, it is deleted with the last interface
+func (p *proxyARPs) commit(configs *vppagent.CommitConfigs) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.ranges) == 0 {
+		configs.Remove().GetVppConfig().ProxyArp = &vppl3.ProxyARP{}
+		return
+	}
+	configs.Update().GetVppConfig().ProxyArp = p.config()
+}
+
+func (p *proxyARPs) config() *vppl3.ProxyARP {
+	names := make([]string, 0, len(p.ranges))
+	for name := range p.ranges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	config := &vppl3.ProxyARP{}
+	for _, name := range names {
+		config.Interfaces = append(config.Interfaces, &vppl3.ProxyARP_Interface{Name: name})
+		config.Ranges = append(config.Ranges, p.ranges[name]...)
+	}
+	return config
+}
+
+// proxyARPRange - returns the range of the IPv4 prefix, proxy-ARP has no IPv6 (proxy-ND) counterpart in vpp-agent
+func proxyARPRange(prefix string) *vppl3.ProxyARP_Range {
+	_, ipNet, err := net.ParseCIDR(prefix)
+	if err != nil || ipNet.IP.To4() == nil {
+		return nil
+	}
+	first := binary.BigEndian.Uint32(ipNet.IP.To4())
+	last := first | ^binary.BigEndian.Uint32(net.IP(ipNet.Mask).To4())
+	lastIP := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(lastIP, last)
+	return &vppl3.ProxyARP_Range{
+		FirstIpAddr: ipNet.IP.String(),
+		LastIpAddr:  lastIP.String(),
+	}
+}
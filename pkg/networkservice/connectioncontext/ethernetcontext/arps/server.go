@@ -0,0 +1,71 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package arps provides networkservice chain elements for setting the static ARP/IPv6 neighbor entries and proxy-ARP
+// on vpp interfaces
+package arps
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type setVppArpServer struct {
+	*options
+}
+
+// NewServer creates a NetworkServiceServer chain element to set the static ARP/IPv6 neighbor entries for the source
+// of the connection and for the IpContext neighbors on the *vpp* side of an interface plugged into the Endpoint.
+// With WithProxyARP the vpp interface also answers ARP for the IPv4 SrcRoutes.
+func NewServer(options ...Option) networkservice.NetworkServiceServer {
+	return &setVppArpServer{options: newOptions(options...)}
+}
+
+func (s *setVppArpServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	conf := vppagent.Config(ctx)
+	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
+		ifaceName := conf.GetVppConfig().GetInterfaces()[index].GetName()
+		connContext := request.GetConnection().GetContext()
+		addArps(conf.GetVppConfig(), ifaceName,
+			connContext.GetIpContext().GetSrcIpAddr(),
+			connContext.GetEthernetContext().GetSrcMac(),
+			connContext.GetIpContext().GetIpNeighbors())
+		if s.proxyARP {
+			globalProxyARPs.set(ctx, ifaceName, connContext.GetIpContext().GetSrcRoutes())
+		}
+	}
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (s *setVppArpServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	conf := vppagent.Config(ctx)
+	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
+		ifaceName := conf.GetVppConfig().GetInterfaces()[index].GetName()
+		addArps(conf.GetVppConfig(), ifaceName,
+			conn.GetContext().GetIpContext().GetSrcIpAddr(),
+			conn.GetContext().GetEthernetContext().GetSrcMac(),
+			conn.GetContext().GetIpContext().GetIpNeighbors())
+		if s.proxyARP {
+			globalProxyARPs.close(ctx, ifaceName)
+		}
+	}
+	return next.Server(ctx).Close(ctx, conn)
+}
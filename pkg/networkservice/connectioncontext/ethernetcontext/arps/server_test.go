@@ -0,0 +1,163 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	memif_mechanisms "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppl3 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l3"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/arps"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	BaseDir        = "BaseDir"
+	SocketFilename = "socketfilename"
+)
+
+func connection(id string, ipContext *networkservice.IPContext, ethernetContext *networkservice.EthernetContext) *networkservice.Connection {
+	return &networkservice.Connection{
+		Id: id,
+		Mechanism: &networkservice.Mechanism{
+			Cls:  cls.LOCAL,
+			Type: memif_mechanisms.MECHANISM,
+			Parameters: map[string]string{
+				memif_mechanisms.SocketFilename: SocketFilename,
+			},
+		},
+		Context: &networkservice.ConnectionContext{
+			IpContext:       ipContext,
+			EthernetContext: ethernetContext,
+		},
+	}
+}
+
+func lastInterfaceName(ctx context.Context, t *testing.T) string {
+	interfaces := vppagent.Config(ctx).GetVppConfig().GetInterfaces()
+	require.Greater(t, len(interfaces), 0)
+	return interfaces[len(interfaces)-1].GetName()
+}
+
+// commit - returns the configs committed for ctx
+func commit(ctx context.Context, t *testing.T, isClose bool) (update, remove *configurator.Config) {
+	require.NoError(t, vppagent.Commit(ctx, isClose, func(u, r *configurator.Config) error {
+		update, remove = u, r
+		return nil
+	}))
+	return update, remove
+}
+
+func TestVppArpServerNeighbors(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		arps.NewServer(),
+	)
+	conn := connection("1", &networkservice.IPContext{
+		SrcIpAddr: "172.16.1.1/30",
+		DstIpAddr: "172.16.1.2/30",
+		IpNeighbors: []*networkservice.IpNeighbor{
+			{Ip: "fd00::3/64", HardwareAddress: "0a:1b:3c:4d:5e:71"},
+			{Ip: "fd00::4"},
+		},
+	}, &networkservice.EthernetContext{SrcMac: "0a:1b:3c:4d:5e:6f"})
+
+	requestCtx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(requestCtx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	closeCtx := vppagent.WithConfig(context.Background())
+	_, err = server.Close(closeCtx, conn)
+	require.NoError(t, err)
+
+	// Close deletes the same entries Request adds
+	for _, ctx := range []context.Context{requestCtx, closeCtx} {
+		ifaceName := lastInterfaceName(ctx, t)
+		assert.Equal(t, []*vppl3.ARPEntry{
+			{Interface: ifaceName, IpAddress: "172.16.1.1", PhysAddress: "0a:1b:3c:4d:5e:6f", Static: true},
+			{Interface: ifaceName, IpAddress: "fd00::3", PhysAddress: "0a:1b:3c:4d:5e:71", Static: true},
+		}, vppagent.Config(ctx).GetVppConfig().GetArps())
+		assert.Nil(t, vppagent.Config(ctx).GetVppConfig().GetProxyArp())
+	}
+}
+
+func TestVppArpServerProxyARP(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		arps.NewServer(arps.WithProxyARP()),
+	)
+	conn1 := connection("1", &networkservice.IPContext{
+		SrcRoutes: []*networkservice.Route{{Prefix: "10.0.0.0/24"}, {Prefix: "fd01::/64"}},
+	}, nil)
+	conn2 := connection("2", &networkservice.IPContext{
+		SrcRoutes: []*networkservice.Route{{Prefix: "10.0.1.16/28"}},
+	}, nil)
+
+	ctx1 := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx1, &networkservice.NetworkServiceRequest{Connection: conn1})
+	require.NoError(t, err)
+	ifaceName1 := lastInterfaceName(ctx1, t)
+	update, remove := commit(ctx1, t, false)
+	assert.Equal(t, &vppl3.ProxyARP{
+		Interfaces: []*vppl3.ProxyARP_Interface{{Name: ifaceName1}},
+		Ranges:     []*vppl3.ProxyARP_Range{{FirstIpAddr: "10.0.0.0", LastIpAddr: "10.0.0.255"}},
+	}, update.GetVppConfig().GetProxyArp())
+	assert.Nil(t, remove)
+
+	// The single global proxy-ARP config carries the ranges of both interfaces
+	ctx2 := vppagent.WithConfig(context.Background())
+	_, err = server.Request(ctx2, &networkservice.NetworkServiceRequest{Connection: conn2})
+	require.NoError(t, err)
+	ifaceName2 := lastInterfaceName(ctx2, t)
+	update, _ = commit(ctx2, t, false)
+	proxyARP := update.GetVppConfig().GetProxyArp()
+	require.NotNil(t, proxyARP)
+	assert.ElementsMatch(t, []*vppl3.ProxyARP_Interface{{Name: ifaceName1}, {Name: ifaceName2}}, proxyARP.GetInterfaces())
+	assert.ElementsMatch(t, []*vppl3.ProxyARP_Range{
+		{FirstIpAddr: "10.0.0.0", LastIpAddr: "10.0.0.255"},
+		{FirstIpAddr: "10.0.1.16", LastIpAddr: "10.0.1.31"},
+	}, proxyARP.GetRanges())
+
+	// Closing a connection updates the global config to the other one, closing the last one deletes it
+	ctx1 = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx1, conn1)
+	require.NoError(t, err)
+	update, remove = commit(ctx1, t, true)
+	assert.Nil(t, remove.GetVppConfig().GetProxyArp())
+	assert.Equal(t, &vppl3.ProxyARP{
+		Interfaces: []*vppl3.ProxyARP_Interface{{Name: ifaceName2}},
+		Ranges:     []*vppl3.ProxyARP_Range{{FirstIpAddr: "10.0.1.16", LastIpAddr: "10.0.1.31"}},
+	}, update.GetVppConfig().GetProxyArp())
+	ctx2 = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx2, conn2)
+	require.NoError(t, err)
+	update, remove = commit(ctx2, t, true)
+	assert.Nil(t, update)
+	assert.NotNil(t, remove.GetVppConfig().GetProxyArp())
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package macgen
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"google.golang.org/grpc"
+)
+
+type macGenClient struct{}
+
+// NewClient creates a NetworkServiceClient chain element setting the SrcMac and DstMac the EthernetContext lacks
+// to the mac addresses generated from the connection id before the Request is sent, so the Endpoint gets the same
+// ones, it has to precede the macaddress elements in the chain.
+func NewClient() networkservice.NetworkServiceClient {
+	return &macGenClient{}
+}
+
+func (m *macGenClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	fillMacs(request.GetConnection())
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// The Endpoint may have changed the connection id
+	fillMacs(conn)
+	return conn, nil
+}
+
+func (m *macGenClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	fillMacs(conn)
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
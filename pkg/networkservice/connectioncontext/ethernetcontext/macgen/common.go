@@ -0,0 +1,70 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package macgen provides networkservice chain elements generating stable mac addresses for the EthernetContext
+package macgen
+
+import (
+	"crypto/sha256"
+	"net"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+const (
+	srcRole = "src"
+	dstRole = "dst"
+)
+
+// SrcMac - returns the mac address generated for the source side of the connection with the id
+func SrcMac(connID string) string {
+	return generate(connID, srcRole)
+}
+
+// DstMac - returns the mac address generated for the destination side of the connection with the id
+func DstMac(connID string) string {
+	return generate(connID, dstRole)
+}
+
+// generate - derives a locally administered unicast mac address from the connection id and the side, so it
+// survives heal and both sides of the connection come to the same one
+func generate(connID, role string) string {
+	sum := sha256.Sum256([]byte(connID + "/" + role))
+	mac := net.HardwareAddr(sum[:6])
+	// Set the locally administered bit and clear the multicast bit
+	mac[0] = (mac[0] | 0x02) &^ 0x01
+	return mac.String()
+}
+
+// fillMacs - sets the generated mac addresses the EthernetContext of the connection lacks
+func fillMacs(conn *networkservice.Connection) {
+	if conn == nil || conn.GetId() == "" {
+		return
+	}
+	if conn.GetContext() == nil {
+		conn.Context = &networkservice.ConnectionContext{}
+	}
+	if conn.GetContext().GetEthernetContext() == nil {
+		conn.GetContext().EthernetContext = &networkservice.EthernetContext{}
+	}
+	ethernetContext := conn.GetContext().GetEthernetContext()
+	if ethernetContext.GetSrcMac() == "" {
+		ethernetContext.SrcMac = SrcMac(conn.GetId())
+	}
+	if ethernetContext.GetDstMac() == "" {
+		ethernetContext.DstMac = DstMac(conn.GetId())
+	}
+}
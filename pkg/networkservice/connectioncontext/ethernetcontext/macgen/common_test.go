@@ -0,0 +1,40 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package macgen_test
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+// ethernetContextServer - saves the EthernetContext the rest of the chain sees
+type ethernetContextServer struct {
+	ethernetContext **networkservice.EthernetContext
+}
+
+func (e *ethernetContextServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	*e.ethernetContext = request.GetConnection().GetContext().GetEthernetContext()
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (e *ethernetContextServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	*e.ethernetContext = conn.GetContext().GetEthernetContext()
+	return next.Server(ctx).Close(ctx, conn)
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package macgen
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+type macGenServer struct{}
+
+// NewServer creates a NetworkServiceServer chain element setting the SrcMac and DstMac the EthernetContext lacks
+// to the mac addresses generated from the connection id, it has to precede the macaddress elements in the chain.
+// The generated mac addresses are returned to the Client in the EthernetContext of the connection.
+func NewServer() networkservice.NetworkServiceServer {
+	return &macGenServer{}
+}
+
+func (m *macGenServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	fillMacs(request.GetConnection())
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (m *macGenServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	fillMacs(conn)
+	return next.Server(ctx).Close(ctx, conn)
+}
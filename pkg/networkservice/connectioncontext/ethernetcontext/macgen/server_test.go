@@ -0,0 +1,83 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package macgen_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/macgen"
+)
+
+func TestGeneratedMacs(t *testing.T) {
+	for _, mac := range []string{macgen.SrcMac("1"), macgen.DstMac("1"), macgen.SrcMac("2")} {
+		hw, err := net.ParseMAC(mac)
+		require.NoError(t, err)
+		assert.Equal(t, byte(0x02), hw[0]&0x03, "locally administered unicast %s", mac)
+	}
+	assert.Equal(t, macgen.SrcMac("1"), macgen.SrcMac("1"))
+	assert.NotEqual(t, macgen.SrcMac("1"), macgen.DstMac("1"))
+	assert.NotEqual(t, macgen.SrcMac("1"), macgen.SrcMac("2"))
+}
+
+func TestMacGenBothSidesAgree(t *testing.T) {
+	var serverContext *networkservice.EthernetContext
+	client := chain.NewNetworkServiceClient(
+		macgen.NewClient(),
+		adapters.NewServerToClient(chain.NewNetworkServiceServer(
+			macgen.NewServer(),
+			&ethernetContextServer{ethernetContext: &serverContext},
+		)),
+	)
+	conn, err := client.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Context: &networkservice.ConnectionContext{
+				EthernetContext: &networkservice.EthernetContext{SrcMac: "0a:1b:3c:4d:5e:6f"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &networkservice.EthernetContext{SrcMac: "0a:1b:3c:4d:5e:6f", DstMac: macgen.DstMac("1")}, conn.GetContext().GetEthernetContext())
+	assert.Equal(t, conn.GetContext().GetEthernetContext(), serverContext)
+}
+
+func TestMacGenServerFillsMissing(t *testing.T) {
+	var serverContext *networkservice.EthernetContext
+	server := chain.NewNetworkServiceServer(
+		macgen.NewServer(),
+		&ethernetContextServer{ethernetContext: &serverContext},
+	)
+	conn, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &networkservice.EthernetContext{SrcMac: macgen.SrcMac("2"), DstMac: macgen.DstMac("2")}, conn.GetContext().GetEthernetContext())
+
+	// Close sees the same mac addresses as Request, so the same config is deleted
+	conn.GetContext().EthernetContext = nil
+	_, err = server.Close(context.Background(), conn)
+	require.NoError(t, err)
+	assert.Equal(t, &networkservice.EthernetContext{SrcMac: macgen.SrcMac("2"), DstMac: macgen.DstMac("2")}, serverContext)
+}
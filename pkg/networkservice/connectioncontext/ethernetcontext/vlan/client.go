@@ -0,0 +1,63 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vlan
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type vlanClient struct{}
+
+// NewClient creates a NetworkServiceClient chain element creating a single tagged sub-interface on the *vpp* side
+// of an interface leaving the Endpoint for the tag from VlanTagKey.
+// It has to precede the mechanism elements, the elements before it use the sub-interface instead of its parent.
+func NewClient() networkservice.NetworkServiceClient {
+	return &vlanClient{}
+}
+
+func (v *vlanClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// The parameters of the selected mechanism are known only after the Request
+	vlanID, err := vlanTag(conn)
+	if err != nil {
+		_, _ = next.Client(ctx).Close(ctx, conn, opts...)
+		return nil, err
+	}
+	appendSubInterface(vppagent.Config(ctx).GetVppConfig(), vlanID)
+	return conn, nil
+}
+
+func (v *vlanClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if vlanID, tagErr := vlanTag(conn); tagErr == nil {
+		appendSubInterface(vppagent.Config(ctx).GetVppConfig(), vlanID)
+	}
+	return rv, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vlan
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+)
+
+const (
+	// VlanTagKey - mechanism parameter or connection label with the 802.1Q tag
+	VlanTagKey = "vlanTag"
+
+	maxVlanTag = 4094
+)
+
+// vlanTag - returns the vlan tag of the connection, zero tag means no vlan. Mechanism parameters take precedence over
+// the connection labels.
+func vlanTag(conn *networkservice.Connection) (uint32, error) {
+	value, ok := conn.GetMechanism().GetParameters()[VlanTagKey]
+	if !ok {
+		value, ok = conn.GetLabels()[VlanTagKey]
+	}
+	if !ok || value == "" {
+		return 0, nil
+	}
+	t, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || t == 0 || t > maxVlanTag {
+		return 0, errors.Errorf("invalid %s %q: must be in [1, %d]", VlanTagKey, value, maxVlanTag)
+	}
+	return uint32(t), nil
+}
+
+// appendSubInterface - appends the sub-interface of the last vpp interface, so the elements using the last interface,
+// like the xconnect and the bridge, use the sub-interface instead of its parent
+func appendSubInterface(vppConfig *vpp.ConfigData, vlanID uint32) {
+	index := len(vppConfig.GetInterfaces()) - 1
+	if index < 0 || vlanID == 0 {
+		return
+	}
+	parent := vppConfig.GetInterfaces()[index]
+	vppConfig.Interfaces = append(vppConfig.Interfaces, &vppinterfaces.Interface{
+		Name:    fmt.Sprintf("%s.%d", parent.GetName(), vlanID),
+		Type:    vppinterfaces.Interface_SUB_INTERFACE,
+		Enabled: true,
+		Link: &vppinterfaces.Interface_Sub{
+			Sub: &vppinterfaces.SubInterface{
+				ParentName:  parent.GetName(),
+				SubId:       vlanID,
+				TagRwOption: vppinterfaces.SubInterface_POP1,
+				Tag1:        vlanID,
+			},
+		},
+	})
+}
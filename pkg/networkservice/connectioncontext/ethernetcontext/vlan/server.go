@@ -0,0 +1,55 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vlan provides networkservice chain elements for placing connections on vpp VLAN sub-interfaces.
+// Only single tagged sub-interfaces are supported, QinQ is out of scope: vpp-agent v3.1.0 creates the sub-interfaces
+// matching a single tag only.
+package vlan
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type vlanServer struct{}
+
+// NewServer creates a NetworkServiceServer chain element creating a single tagged sub-interface on the *vpp* side
+// of an interface plugged into the Endpoint for the tag from VlanTagKey.
+// It has to follow the mechanism elements, the elements after it use the sub-interface instead of its parent.
+func NewServer() networkservice.NetworkServiceServer {
+	return &vlanServer{}
+}
+
+func (v *vlanServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	vlanID, err := vlanTag(request.GetConnection())
+	if err != nil {
+		return nil, err
+	}
+	appendSubInterface(vppagent.Config(ctx).GetVppConfig(), vlanID)
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (v *vlanServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	if vlanID, err := vlanTag(conn); err == nil {
+		appendSubInterface(vppagent.Config(ctx).GetVppConfig(), vlanID)
+	}
+	return next.Server(ctx).Close(ctx, conn)
+}
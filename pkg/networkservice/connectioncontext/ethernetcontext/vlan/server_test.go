@@ -0,0 +1,147 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vlan_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	memif_mechanisms "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	l2 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l2"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/vlan"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/xconnect/l2xconnect"
+)
+
+const (
+	BaseDir        = "BaseDir"
+	SocketFilename = "socketfilename"
+)
+
+func request(parameters, labels map[string]string) *networkservice.NetworkServiceRequest {
+	parameters[memif_mechanisms.SocketFilename] = SocketFilename
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Mechanism: &networkservice.Mechanism{
+				Cls:        cls.LOCAL,
+				Type:       memif_mechanisms.MECHANISM,
+				Parameters: parameters,
+			},
+			Labels: labels,
+		},
+	}
+}
+
+func TestVlanServerSingleTag(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		vlan.NewServer(),
+	)
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, request(map[string]string{}, map[string]string{vlan.VlanTagKey: "100"}))
+	require.NoError(t, err)
+
+	interfaces := vppagent.Config(ctx).GetVppConfig().GetInterfaces()
+	require.Len(t, interfaces, 2)
+	parent := interfaces[0].GetName()
+	assert.Equal(t, parent+".100", interfaces[1].GetName())
+	assert.Equal(t, vppinterfaces.Interface_SUB_INTERFACE, interfaces[1].GetType())
+	assert.True(t, interfaces[1].GetEnabled())
+	assert.Equal(t, &vppinterfaces.SubInterface{
+		ParentName:  parent,
+		SubId:       100,
+		TagRwOption: vppinterfaces.SubInterface_POP1,
+		Tag1:        100,
+	}, interfaces[1].GetSub())
+}
+
+func TestVlanClientTagPrecedence(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	client := chain.NewNetworkServiceClient(
+		vlan.NewClient(),
+		memif.NewClient(BaseDir),
+	)
+	// Mechanism parameters take precedence over the labels
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := client.Request(ctx, request(
+		map[string]string{vlan.VlanTagKey: "200"},
+		map[string]string{vlan.VlanTagKey: "100"},
+	))
+	require.NoError(t, err)
+	interfaces := vppagent.Config(ctx).GetVppConfig().GetInterfaces()
+	require.Len(t, interfaces, 2)
+	assert.Equal(t, interfaces[0].GetName()+".200", interfaces[1].GetName())
+}
+
+func TestVlanNoTagAndInvalidTags(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		vlan.NewServer(),
+	)
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, request(map[string]string{}, nil))
+	require.NoError(t, err)
+	assert.Len(t, vppagent.Config(ctx).GetVppConfig().GetInterfaces(), 1)
+
+	for _, parameters := range []map[string]string{
+		{vlan.VlanTagKey: "4095"},
+		{vlan.VlanTagKey: "vlan"},
+		{vlan.VlanTagKey: "0"},
+	} {
+		_, err = server.Request(vppagent.WithConfig(context.Background()), request(parameters, nil))
+		assert.Error(t, err, "%v", parameters)
+	}
+}
+
+func TestVlanXConnectUsesSubInterface(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		adapters.NewClientToServer(chain.NewNetworkServiceClient(
+			l2xconnect.NewClient(),
+			vlan.NewClient(),
+			memif.NewClient(BaseDir),
+		)),
+	)
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, request(map[string]string{vlan.VlanTagKey: "100"}, nil))
+	require.NoError(t, err)
+
+	vppConfig := vppagent.Config(ctx).GetVppConfig()
+	require.Len(t, vppConfig.GetInterfaces(), 3)
+	serverName := vppConfig.GetInterfaces()[0].GetName()
+	subName := vppConfig.GetInterfaces()[2].GetName()
+	assert.Equal(t, vppConfig.GetInterfaces()[1].GetName()+".100", subName)
+	assert.Equal(t, []*l2.XConnectPair{
+		{ReceiveInterface: serverName, TransmitInterface: subName},
+		{ReceiveInterface: subName, TransmitInterface: serverName},
+	}, vppConfig.GetXconnectPairs())
+}
@@ -30,7 +30,9 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 )
 
-type setVppIPClient struct{}
+type setVppIPClient struct {
+	*options
+}
 
 // NewClient creates a NetworkServiceClient chain element to set the ip address on a vpp interface
 // It sets the IP Address on the *vpp* side of an interface leaving the
@@ -54,8 +56,9 @@ type setVppIPClient struct{}
 //                              |                           |
 //                              +---------------------------+
 //
-func NewClient() networkservice.NetworkServiceClient {
-	return &setVppIPClient{}
+// The addresses are merged with the ones already set on the interface by the other elements.
+func NewClient(options ...Option) networkservice.NetworkServiceClient {
+	return &setVppIPClient{options: newOptions(options...)}
 }
 
 func (s *setVppIPClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
@@ -65,7 +68,13 @@ func (s *setVppIPClient) Request(ctx context.Context, request *networkservice.Ne
 	}
 	conf := vppagent.Config(ctx)
 	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
-		conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{conn.GetContext().GetIpContext().GetSrcIpAddr()}
+		iface := conf.GetVppConfig().GetInterfaces()[index]
+		addresses, mergeErr := s.mergeAddresses(iface.GetIpAddresses(), conn, conn.GetContext().GetIpContext().GetSrcIpAddr())
+		if mergeErr != nil {
+			_, _ = next.Client(ctx).Close(ctx, conn, opts...)
+			return nil, mergeErr
+		}
+		iface.IpAddresses = addresses
 	}
 	return conn, nil
 }
@@ -74,7 +83,10 @@ func (s *setVppIPClient) Close(ctx context.Context, conn *networkservice.Connect
 	e, err := next.Client(ctx).Close(ctx, conn, opts...)
 	conf := vppagent.Config(ctx)
 	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
-		conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{conn.GetContext().GetIpContext().GetSrcIpAddr()}
+		iface := conf.GetVppConfig().GetInterfaces()[index]
+		if addresses, mergeErr := s.mergeAddresses(iface.GetIpAddresses(), conn, conn.GetContext().GetIpContext().GetSrcIpAddr()); mergeErr == nil {
+			iface.IpAddresses = addresses
+		}
 	}
 	return e, err
 }
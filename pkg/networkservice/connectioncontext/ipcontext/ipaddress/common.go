@@ -0,0 +1,82 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipaddress
+
+import (
+	"net"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/pkg/errors"
+)
+
+// AddressesFunc - returns the secondary addresses of the connection for the vpp interface
+type AddressesFunc func(conn *networkservice.Connection) []string
+
+// Option - option for NewClient and NewServer
+type Option func(o *options)
+
+type options struct {
+	// TODO - take the address lists from the IpContext once the API carries more than one address per side
+	secondaryAddresses AddressesFunc
+}
+
+// WithSecondaryAddresses - adds the addresses returned by secondaryAddresses after the address from the IpContext,
+//                          for example the IPv6 address of a dual-stack connection.  The IpContext carries
+//                          a single address per side, so the other addresses come from secondaryAddresses.
+func WithSecondaryAddresses(secondaryAddresses AddressesFunc) Option {
+	return func(o *options) {
+		o.secondaryAddresses = secondaryAddresses
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// mergeAddresses - returns the addresses already set on the interface by the other elements with the primary and
+// the secondary addresses of the connection appended, the duplicates are skipped
+func (o *options) mergeAddresses(ifaceAddresses []string, conn *networkservice.Connection, primary string) ([]string, error) {
+	addresses := []string{primary}
+	if o.secondaryAddresses != nil {
+		secondary := o.secondaryAddresses(conn)
+		for _, addr := range secondary {
+			if _, _, err := net.ParseCIDR(addr); err != nil && net.ParseIP(addr) == nil {
+				return nil, errors.Errorf("invalid secondary address %q", addr)
+			}
+		}
+		addresses = append(addresses, secondary...)
+	}
+	for _, addr := range addresses {
+		if addr != "" && !contains(ifaceAddresses, addr) {
+			ifaceAddresses = append(ifaceAddresses, addr)
+		}
+	}
+	return ifaceAddresses, nil
+}
+
+func contains(addresses []string, addr string) bool {
+	for _, a := range addresses {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
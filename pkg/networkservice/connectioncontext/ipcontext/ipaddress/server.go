@@ -27,7 +27,9 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 )
 
-type setVppIPServer struct{}
+type setVppIPServer struct {
+	*options
+}
 
 // NewServer creates a NetworkServiceServer chain element to set the ip address on a vpp interface
 // It sets the IP Address on the *vpp* side of an interface plugged into the
@@ -51,15 +53,21 @@ type setVppIPServer struct{}
 //                              |                           |
 //                              +---------------------------+
 //
-func NewServer() networkservice.NetworkServiceServer {
-	return &setVppIPServer{}
+// The addresses are merged with the ones already set on the interface by the other elements.
+func NewServer(options ...Option) networkservice.NetworkServiceServer {
+	return &setVppIPServer{options: newOptions(options...)}
 }
 
 func (s *setVppIPServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
 	conf := vppagent.Config(ctx)
 	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
+		iface := conf.GetVppConfig().GetInterfaces()[index]
 		dstIP := request.GetConnection().GetContext().GetIpContext().GetDstIpAddr()
-		conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{dstIP}
+		addresses, err := s.mergeAddresses(iface.GetIpAddresses(), request.GetConnection(), dstIP)
+		if err != nil {
+			return nil, err
+		}
+		iface.IpAddresses = addresses
 	}
 	return next.Server(ctx).Request(ctx, request)
 }
@@ -67,8 +75,11 @@ func (s *setVppIPServer) Request(ctx context.Context, request *networkservice.Ne
 func (s *setVppIPServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
 	conf := vppagent.Config(ctx)
 	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
+		iface := conf.GetVppConfig().GetInterfaces()[index]
 		dstIP := conn.GetContext().GetIpContext().GetDstIpAddr()
-		conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{dstIP}
+		if addresses, err := s.mergeAddresses(iface.GetIpAddresses(), conn, dstIP); err == nil {
+			iface.IpAddresses = addresses
+		}
 	}
 	return next.Server(ctx).Close(ctx, conn)
 }
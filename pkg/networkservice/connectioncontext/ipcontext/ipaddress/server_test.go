@@ -25,6 +25,7 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
 	memif_mechanisms "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/checks/checkcontext"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -104,3 +105,40 @@ func TestSetIPVppServerPropagatesError(t *testing.T) {
 	_, err = server.Close(vppagent.WithConfig(context.Background()), serverRequest().GetConnection())
 	assert.NotNil(t, err)
 }
+
+func TestSetIPVppServerDualStack(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		checkcontext.NewServer(t, func(t *testing.T, ctx context.Context) {
+			// Address put on the interface by another element is kept
+			conf := vppagent.Config(ctx)
+			iface := conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1]
+			iface.IpAddresses = append(iface.IpAddresses, "10.0.0.1/32")
+		}),
+		ipaddress.NewServer(ipaddress.WithSecondaryAddresses(func(*networkservice.Connection) []string {
+			return []string{"fd00::2/127", "10.0.0.1/32", "172.16.2.2/24"}
+		})),
+	)
+	request := serverRequest()
+	request.GetConnection().GetContext().GetIpContext().DstIpAddr = "172.16.1.2/30"
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, request)
+	require.NoError(t, err)
+
+	conf := vppagent.Config(ctx)
+	ipAddresses := conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].GetIpAddresses()
+	assert.Equal(t, []string{"10.0.0.1/32", "172.16.1.2/30", "fd00::2/127", "172.16.2.2/24"}, ipAddresses)
+}
+
+func TestSetIPVppServerInvalidSecondaryAddress(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		ipaddress.NewServer(ipaddress.WithSecondaryAddresses(func(*networkservice.Connection) []string {
+			return []string{"fd00::2/129"}
+		})),
+	)
+	_, err := server.Request(vppagent.WithConfig(context.Background()), serverRequest())
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type setVppRoutesClient struct {
+	*options
+}
+
+// NewClient creates a NetworkServiceClient chain element to set the SrcRoutes via the DstIpAddr on the *vpp* side
+// of an interface leaving the Endpoint.  With WithPerConnectionVRF the interface and its routes are put into
+// a VRF of their own.
+func NewClient(options ...Option) networkservice.NetworkServiceClient {
+	return &setVppRoutesClient{options: newOptions(options...)}
+}
+
+func (s *setVppRoutesClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	conf := vppagent.Config(ctx)
+	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
+		ipContext := conn.GetContext().GetIpContext()
+		if _, err = s.addRoutes(conf.GetVppConfig(), conf.GetVppConfig().GetInterfaces()[index], ipContext.GetSrcRoutes(), ipContext.GetDstIpAddr()); err != nil {
+			_, _ = next.Client(ctx).Close(ctx, conn, opts...)
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (s *setVppRoutesClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	e, err := next.Client(ctx).Close(ctx, conn, opts...)
+	conf := vppagent.Config(ctx)
+	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
+		iface := conf.GetVppConfig().GetInterfaces()[index]
+		ipContext := conn.GetContext().GetIpContext()
+		_, _ = s.addRoutes(conf.GetVppConfig(), iface, ipContext.GetSrcRoutes(), ipContext.GetDstIpAddr())
+		s.release(iface)
+	}
+	return e, err
+}
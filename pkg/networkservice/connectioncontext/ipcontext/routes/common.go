@@ -0,0 +1,159 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes
+
+import (
+	"net"
+	"sync"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	vppl3 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l3"
+)
+
+// Option - option for NewClient and NewServer
+type Option func(o *options)
+
+type options struct {
+	perConnectionVRF bool
+	vrfFirst         uint32
+	vrfLast          uint32
+}
+
+// WithPerConnectionVRF - puts the vpp interface and the routes of every connection into a VRF of its own, the VRF
+//                        ids are allocated from [first, last]. first must be at least 1, VRF 0 is the default table
+//                        shared by all the interfaces.
+func WithPerConnectionVRF(first, last uint32) Option {
+	return func(o *options) {
+		o.perConnectionVRF = true
+		o.vrfFirst = first
+		o.vrfLast = last
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// addRoutes - adds the routes via the gateway on the interface, into the VRF of the interface if the VRFs are enabled.
+// allocated is true if the VRF is allocated by this call, so the caller releases it if the Request fails, the VRF
+// allocated by this call is released if addRoutes fails.
+func (o *options) addRoutes(vppConfig *vpp.ConfigData, iface *vppinterfaces.Interface, routes []*networkservice.Route, gwAddr string) (allocated bool, err error) {
+	var vrfID uint32
+	if o.perConnectionVRF {
+		if vrfID, allocated, err = globalVRFs.allocate(iface.GetName(), o.vrfFirst, o.vrfLast); err != nil {
+			return false, err
+		}
+		iface.Vrf = vrfID
+		vppConfig.Vrfs = append(vppConfig.Vrfs,
+			&vppl3.VrfTable{Id: vrfID, Protocol: vppl3.VrfTable_IPV4, Label: iface.GetName()},
+			&vppl3.VrfTable{Id: vrfID, Protocol: vppl3.VrfTable_IPV6, Label: iface.GetName()},
+		)
+	}
+	gwIP := net.ParseIP(extractCleanIPAddress(gwAddr))
+	prefixes := make(map[string]bool)
+	for _, route := range routes {
+		_, dst, parseErr := net.ParseCIDR(route.GetPrefix())
+		if parseErr != nil {
+			if allocated {
+				globalVRFs.release(iface.GetName())
+			}
+			return false, errors.Wrapf(parseErr, "invalid route prefix %s", route.GetPrefix())
+		}
+		if prefixes[dst.String()] {
+			continue
+		}
+		prefixes[dst.String()] = true
+		vppRoute := &vppl3.Route{
+			Type:              vppl3.Route_INTRA_VRF,
+			VrfId:             vrfID,
+			DstNetwork:        dst.String(),
+			OutgoingInterface: iface.GetName(),
+		}
+		// A gateway of the other family can't be used, the route goes directly through the interface then
+		if gwIP != nil && (gwIP.To4() == nil) == (dst.IP.To4() == nil) {
+			vppRoute.NextHopAddr = gwIP.String()
+		}
+		vppConfig.Routes = append(vppConfig.Routes, vppRoute)
+	}
+	return allocated, nil
+}
+
+// release - releases the VRF of the interface after Close or after a failed Request allocating it
+func (o *options) release(iface *vppinterfaces.Interface) {
+	if o.perConnectionVRF {
+		globalVRFs.release(iface.GetName())
+	}
+}
+
+func extractCleanIPAddress(addr string) string {
+	ip, _, err := net.ParseCIDR(addr)
+	if err == nil {
+		return ip.String()
+	}
+	return addr
+}
+
+// vrfs - VRF ids are a global vpp resource, so they are allocated for all the elements of the process here
+type vrfs struct {
+	ids  map[string]uint32
+	used map[uint32]bool
+	mu   sync.Mutex
+}
+
+var globalVRFs = &vrfs{
+	ids:  make(map[string]uint32),
+	used: make(map[uint32]bool),
+}
+
+// allocate - returns the VRF id of the interface, allocating a free one from [first, last] on the first call,
+// allocated is true for the first call
+func (v *vrfs) allocate(ifaceName string, first, last uint32) (id uint32, allocated bool, err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if id, ok := v.ids[ifaceName]; ok {
+		return id, false, nil
+	}
+	if first == 0 {
+		return 0, false, errors.Errorf("VRF 0 is the default table and can't be allocated to interface %s", ifaceName)
+	}
+	for id := first; id >= first && id <= last; id++ {
+		if !v.used[id] {
+			v.used[id] = true
+			v.ids[ifaceName] = id
+			return id, true, nil
+		}
+	}
+	return 0, false, errors.Errorf("no free VRF id in [%d, %d] for interface %s", first, last, ifaceName)
+}
+
+func (v *vrfs) release(ifaceName string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if id, ok := v.ids[ifaceName]; ok {
+		delete(v.used, id)
+		delete(v.ids, ifaceName)
+	}
+}
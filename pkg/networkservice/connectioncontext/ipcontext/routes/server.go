@@ -0,0 +1,73 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package routes provides networkservice chain elements for setting the routes from the connection context on
+// vpp interfaces
+package routes
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type setVppRoutesServer struct {
+	*options
+}
+
+// NewServer creates a NetworkServiceServer chain element to set the DstRoutes via the SrcIpAddr on the *vpp* side
+// of an interface plugged into the Endpoint.  With WithPerConnectionVRF the interface and its routes are put
+// into a VRF of their own.
+func NewServer(options ...Option) networkservice.NetworkServiceServer {
+	return &setVppRoutesServer{options: newOptions(options...)}
+}
+
+func (s *setVppRoutesServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	conf := vppagent.Config(ctx)
+	index := len(conf.GetVppConfig().GetInterfaces()) - 1
+	if index < 0 {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	iface := conf.GetVppConfig().GetInterfaces()[index]
+	ipContext := request.GetConnection().GetContext().GetIpContext()
+	allocated, err := s.addRoutes(conf.GetVppConfig(), iface, ipContext.GetDstRoutes(), ipContext.GetSrcIpAddr())
+	if err != nil {
+		return nil, err
+	}
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil && allocated {
+		s.release(iface)
+	}
+	return conn, err
+}
+
+func (s *setVppRoutesServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	conf := vppagent.Config(ctx)
+	index := len(conf.GetVppConfig().GetInterfaces()) - 1
+	if index < 0 {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	iface := conf.GetVppConfig().GetInterfaces()[index]
+	ipContext := conn.GetContext().GetIpContext()
+	_, _ = s.addRoutes(conf.GetVppConfig(), iface, ipContext.GetDstRoutes(), ipContext.GetSrcIpAddr())
+	rv, err := next.Server(ctx).Close(ctx, conn)
+	s.release(iface)
+	return rv, err
+}
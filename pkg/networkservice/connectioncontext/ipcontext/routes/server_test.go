@@ -0,0 +1,180 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	memif_mechanisms "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vppl3 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l3"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/routes"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	BaseDir        = "BaseDir"
+	SocketFilename = "socketfilename"
+)
+
+func connection(id string, ipContext *networkservice.IPContext) *networkservice.Connection {
+	return &networkservice.Connection{
+		Id: id,
+		Mechanism: &networkservice.Mechanism{
+			Cls:  cls.LOCAL,
+			Type: memif_mechanisms.MECHANISM,
+			Parameters: map[string]string{
+				memif_mechanisms.SocketFilename: SocketFilename,
+			},
+		},
+		Context: &networkservice.ConnectionContext{
+			IpContext: ipContext,
+		},
+	}
+}
+
+func TestVppRoutesServer(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		routes.NewServer(),
+	)
+	conn := connection("1", &networkservice.IPContext{
+		SrcIpAddr: "172.16.1.1/30",
+		DstIpAddr: "172.16.1.2/30",
+		DstRoutes: []*networkservice.Route{
+			{Prefix: "10.0.0.0/8"},
+			{Prefix: "10.0.0.0/8"},
+			{Prefix: "fd01::/64"},
+		},
+		SrcRoutes: []*networkservice.Route{
+			{Prefix: "192.168.0.0/16"},
+		},
+	})
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	vppConfig := vppagent.Config(ctx).GetVppConfig()
+	require.Len(t, vppConfig.GetInterfaces(), 1)
+	ifaceName := vppConfig.GetInterfaces()[0].GetName()
+	assert.Equal(t, []*vppl3.Route{
+		{DstNetwork: "10.0.0.0/8", NextHopAddr: "172.16.1.1", OutgoingInterface: ifaceName},
+		{DstNetwork: "fd01::/64", OutgoingInterface: ifaceName},
+	}, vppConfig.GetRoutes())
+	assert.Empty(t, vppConfig.GetVrfs())
+	assert.Zero(t, vppConfig.GetInterfaces()[0].GetVrf())
+
+	conn.GetContext().GetIpContext().DstRoutes = []*networkservice.Route{{Prefix: "10.0.0.0/33"}}
+	_, err = server.Request(vppagent.WithConfig(context.Background()), &networkservice.NetworkServiceRequest{Connection: conn})
+	assert.Error(t, err)
+}
+
+func TestVppRoutesPerConnectionVRF(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		routes.NewServer(routes.WithPerConnectionVRF(10, 11)),
+	)
+	client := chain.NewNetworkServiceClient(
+		routes.NewClient(routes.WithPerConnectionVRF(10, 11)),
+		memif.NewClient(BaseDir),
+	)
+	serverConn := connection("1", &networkservice.IPContext{
+		SrcIpAddr: "172.16.1.1/30",
+		DstRoutes: []*networkservice.Route{{Prefix: "10.0.0.0/8"}},
+	})
+	clientConn := connection("2", &networkservice.IPContext{
+		DstIpAddr: "fd00::2/127",
+		SrcRoutes: []*networkservice.Route{{Prefix: "fd01::/64"}},
+	})
+
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: serverConn})
+	require.NoError(t, err)
+	vppConfig := vppagent.Config(ctx).GetVppConfig()
+	ifaceName := vppConfig.GetInterfaces()[0].GetName()
+	assert.Equal(t, uint32(10), vppConfig.GetInterfaces()[0].GetVrf())
+	assert.Equal(t, []*vppl3.VrfTable{
+		{Id: 10, Protocol: vppl3.VrfTable_IPV4, Label: ifaceName},
+		{Id: 10, Protocol: vppl3.VrfTable_IPV6, Label: ifaceName},
+	}, vppConfig.GetVrfs())
+	assert.Equal(t, []*vppl3.Route{
+		{VrfId: 10, DstNetwork: "10.0.0.0/8", NextHopAddr: "172.16.1.1", OutgoingInterface: ifaceName},
+	}, vppConfig.GetRoutes())
+
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = client.Request(ctx, &networkservice.NetworkServiceRequest{Connection: clientConn})
+	require.NoError(t, err)
+	vppConfig = vppagent.Config(ctx).GetVppConfig()
+	assert.Equal(t, uint32(11), vppConfig.GetInterfaces()[0].GetVrf())
+	assert.Equal(t, "fd00::2", vppConfig.GetRoutes()[0].GetNextHopAddr())
+
+	// The VRFs are exhausted until the server connection is closed
+	_, err = server.Request(vppagent.WithConfig(context.Background()), &networkservice.NetworkServiceRequest{Connection: connection("3", nil)})
+	assert.Error(t, err)
+
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, serverConn)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), vppagent.Config(ctx).GetVppConfig().GetRoutes()[0].GetVrfId())
+	_, err = server.Request(vppagent.WithConfig(context.Background()), &networkservice.NetworkServiceRequest{Connection: connection("3", nil)})
+	assert.NoError(t, err)
+}
+
+func TestVppRoutesReleasesVRFOnFailure(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	failingServer := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		routes.NewServer(routes.WithPerConnectionVRF(1, 1)),
+		injecterror.NewServer(),
+	)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		routes.NewServer(routes.WithPerConnectionVRF(1, 1)),
+	)
+
+	_, err := failingServer.Request(vppagent.WithConfig(context.Background()), &networkservice.NetworkServiceRequest{Connection: connection("4", nil)})
+	require.Error(t, err)
+
+	// The only VRF of the range is free again after the failed Request
+	ctx := vppagent.WithConfig(context.Background())
+	_, err = server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: connection("4", nil)})
+	require.NoError(t, err)
+	assert.Len(t, vppagent.Config(ctx).GetVppConfig().GetVrfs(), 2)
+	_, err = server.Close(vppagent.WithConfig(context.Background()), connection("4", nil))
+	require.NoError(t, err)
+}
+
+func TestVppRoutesRejectsDefaultVRF(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		routes.NewServer(routes.WithPerConnectionVRF(0, 1)),
+	)
+	_, err := server.Request(vppagent.WithConfig(context.Background()), &networkservice.NetworkServiceRequest{Connection: connection("5", nil)})
+	require.Error(t, err)
+}
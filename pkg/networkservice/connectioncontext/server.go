@@ -23,8 +23,12 @@ import (
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/arps"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/macaddress"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/macgen"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/vlan"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/ipaddress"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/routes"
 )
 
 // NewServer creates a NetworkServiceServer chain element to set the ip address on a vpp interface
@@ -51,7 +55,11 @@ import (
 //
 func NewServer() networkservice.NetworkServiceServer {
 	return chain.NewNetworkServiceServer(
+		macgen.NewServer(),
+		vlan.NewServer(),
 		ipaddress.NewServer(),
+		routes.NewServer(),
 		macaddress.NewServer(),
+		arps.NewServer(),
 	)
 }
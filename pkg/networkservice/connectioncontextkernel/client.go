@@ -23,6 +23,7 @@ import (
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/macgen"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/ipaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/routes"
 )
@@ -52,6 +53,7 @@ import (
 //
 func NewClient() networkservice.NetworkServiceClient {
 	return chain.NewNetworkServiceClient(
+		macgen.NewClient(),
 		routes.NewClient(),
 		ipaddress.NewClient(),
 	)
@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnscontext
+
+import (
+	"sync"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+)
+
+// dnsConfigurator - remembers which resolv.conf each connection has configured, so it can be cleaned up on Close
+// and on a refresh dropping the DNS context even if the netns is gone already
+type dnsConfigurator struct {
+	resolvConf ResolvConfFunc
+	filenames  map[string]string
+	mu         sync.Mutex
+}
+
+func newDNSConfigurator(opts ...Option) *dnsConfigurator {
+	return &dnsConfigurator{
+		resolvConf: newOptions(opts...).resolvConf,
+		filenames:  make(map[string]string),
+	}
+}
+
+// configure - applies the configs of the connection, no configs remove the ones applied before
+func (d *dnsConfigurator) configure(conn *networkservice.Connection, configs []*networkservice.DNSConfig) error {
+	if kernel.ToMechanism(conn.GetMechanism()) == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	filename, ok := d.filenames[conn.GetId()]
+	if len(configs) == 0 {
+		if !ok {
+			return nil
+		}
+		delete(d.filenames, conn.GetId())
+		return files.set(filename, conn.GetId(), nil)
+	}
+	newFilename, err := d.resolvConf(conn)
+	if err != nil {
+		return err
+	}
+	if ok && newFilename != filename {
+		if err = files.set(filename, conn.GetId(), nil); err != nil {
+			return err
+		}
+		delete(d.filenames, conn.GetId())
+	}
+	if newFilename == "" {
+		return nil
+	}
+	d.filenames[conn.GetId()] = newFilename
+	return files.set(newFilename, conn.GetId(), configs)
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnscontext
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/sirupsen/logrus"
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
+)
+
+const (
+	// namedNetNSConfDir - per network namespace configuration directory, "ip netns exec" bind mounts its files over /etc
+	namedNetNSConfDir = "/etc/netns"
+	resolvConfName    = "resolv.conf"
+)
+
+var (
+	// procNetNSFilename - netns file of a process or of one of its threads
+	procNetNSFilename = regexp.MustCompile(`^/proc/(\d+)/(task/\d+/)?ns/net$`)
+	// namedNetNSFilename - netns file of a named netns, "ip netns add" creates it in /var/run/netns
+	namedNetNSFilename = regexp.MustCompile(`^(/var)?/run/netns/([^/]+)$`)
+)
+
+// ResolvConfFunc - returns the resolv.conf file to configure for the connection, "" if there is none: the DNS context
+//                  of the connection isn't applied then
+type ResolvConfFunc func(conn *networkservice.Connection) (string, error)
+
+// Option - option for NewServer
+type Option func(o *options)
+
+type options struct {
+	resolvConf ResolvConfFunc
+}
+
+// WithResolvConfFunc - sets the func locating the resolv.conf for the connection, for example the config of a local
+//                      DNS forwarder, by default the resolv.conf of the workload owning the kernel mechanism netns
+func WithResolvConfFunc(resolvConf ResolvConfFunc) Option {
+	return func(o *options) {
+		o.resolvConf = resolvConf
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		resolvConf: WorkloadResolvConf(netnsurl.NewResolver()),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WorkloadResolvConf - returns ResolvConfFunc locating the resolv.conf of the workload by the NetNSURL of the kernel
+//                      mechanism: /etc/netns/<name>/resolv.conf for the named netns and the resolv.conf in the root
+//                      of the process for the netns of a process. The DNS context isn't applied to the other netns,
+//                      e.g. the ones referenced by an open file, as the workload can't be told from them.
+func WorkloadResolvConf(resolver *netnsurl.Resolver) ResolvConfFunc {
+	return func(conn *networkservice.Connection) (string, error) {
+		mechanism := kernel.ToMechanism(conn.GetMechanism())
+		netNS, err := resolver.Resolve(mechanism.GetNetNSURL())
+		if err != nil {
+			logrus.Warnf("can't locate resolv.conf of the workload of the connection %s: %v", conn.GetId(), err)
+			return "", nil
+		}
+		if netNS.Namespace.GetType() == linuxnamespace.NetNamespace_NSID {
+			return filepath.Join(namedNetNSConfDir, netNS.Namespace.GetReference(), resolvConfName), nil
+		}
+		if match := namedNetNSFilename.FindStringSubmatch(netNS.Filename); match != nil {
+			return filepath.Join(namedNetNSConfDir, match[2], resolvConfName), nil
+		}
+		if match := procNetNSFilename.FindStringSubmatch(netNS.Filename); match != nil {
+			return filepath.Join("/proc", match[1], "root", "etc", resolvConfName), nil
+		}
+		logrus.Warnf("can't locate resolv.conf of the workload of the connection %s in netns %s, the DNS context isn't applied",
+			conn.GetId(), netNS.Filename)
+		return "", nil
+	}
+}
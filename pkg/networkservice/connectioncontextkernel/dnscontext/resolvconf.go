@@ -0,0 +1,253 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnscontext
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/pkg/errors"
+)
+
+const (
+	resolvConfMode = 0644
+	// backupSuffix - the original resolv.conf is kept next to it until it is restored, so a restart doesn't lose it
+	backupSuffix = ".nsm-backup"
+	// createdSuffix - marks a resolv.conf which didn't exist before the first connection, so it is removed on restore
+	createdSuffix = ".nsm-created"
+)
+
+// resolvConf - state of a resolv.conf shared by the connections
+type resolvConf struct {
+	original []byte
+	existed  bool
+	mode     os.FileMode
+	// connIDs keeps the order the connections were added in
+	connIDs []string
+	configs map[string][]*networkservice.DNSConfig
+}
+
+// resolvConfs - resolv.conf files are shared by all the elements of the process, so several connections and several
+// chains configuring the same workload merge into one file
+type resolvConfs struct {
+	files map[string]*resolvConf
+	mu    sync.Mutex
+}
+
+var files = &resolvConfs{
+	files: make(map[string]*resolvConf),
+}
+
+// set - sets the configs of the connection and rewrites the file, no configs remove the connection
+func (r *resolvConfs) set(filename, connID string, configs []*networkservice.DNSConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, ok := r.files[filename]
+	if !ok {
+		if len(configs) == 0 {
+			return nil
+		}
+		var err error
+		if file, err = readResolvConf(filename); err != nil {
+			return err
+		}
+		r.files[filename] = file
+	}
+	if len(configs) == 0 {
+		file.remove(connID)
+	} else {
+		file.add(connID, configs)
+	}
+	if len(file.connIDs) == 0 {
+		delete(r.files, filename)
+		return file.restore(filename)
+	}
+	return errors.Wrapf(writeFile(filename, file.merge(), file.mode), "can't write %s", filename)
+}
+
+// readResolvConf - reads the original state of the file, from the backup if there is one left by the previous run,
+// and backs it up before the file is written for the first time
+func readResolvConf(filename string) (*resolvConf, error) {
+	file := &resolvConf{
+		mode:    resolvConfMode,
+		configs: make(map[string][]*networkservice.DNSConfig),
+	}
+	if _, err := os.Stat(filename + createdSuffix); err == nil {
+		return file, nil
+	}
+	backupFilename := filename + backupSuffix
+	info, err := os.Stat(backupFilename)
+	if os.IsNotExist(err) {
+		return backupResolvConf(filename, file)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't stat %s", backupFilename)
+	}
+	/* #nosec */
+	if file.original, err = ioutil.ReadFile(backupFilename); err != nil {
+		return nil, errors.Wrapf(err, "can't read %s", backupFilename)
+	}
+	file.existed = true
+	file.mode = info.Mode().Perm()
+	return file, nil
+}
+
+func backupResolvConf(filename string, file *resolvConf) (*resolvConf, error) {
+	info, err := os.Stat(filename)
+	if os.IsNotExist(err) {
+		if err = ioutil.WriteFile(filename+createdSuffix, nil, resolvConfMode); err != nil {
+			return nil, errors.Wrapf(err, "can't create %s", filename+createdSuffix)
+		}
+		return file, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't stat %s", filename)
+	}
+	/* #nosec */
+	if file.original, err = ioutil.ReadFile(filename); err != nil {
+		return nil, errors.Wrapf(err, "can't read %s", filename)
+	}
+	file.existed = true
+	file.mode = info.Mode().Perm()
+	if err = writeFile(filename+backupSuffix, file.original, file.mode); err != nil {
+		return nil, errors.Wrapf(err, "can't back up %s", filename)
+	}
+	return file, nil
+}
+
+// writeFile - writes the file atomically through a temporary file renamed over it, so the resolver never reads
+// a partially written file.  A resolv.conf bind mounted into a container can't be replaced, it is written in place.
+func writeFile(filename string, data []byte, mode os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp.Name(), filename)
+	if linkErr, ok := err.(*os.LinkError); ok && linkErr.Err == syscall.EBUSY {
+		return ioutil.WriteFile(filename, data, mode)
+	}
+	return err
+}
+
+func (f *resolvConf) add(connID string, configs []*networkservice.DNSConfig) {
+	if _, exists := f.configs[connID]; !exists {
+		f.connIDs = append(f.connIDs, connID)
+	}
+	f.configs[connID] = configs
+}
+
+func (f *resolvConf) remove(connID string) {
+	delete(f.configs, connID)
+	for i, id := range f.connIDs {
+		if id == connID {
+			f.connIDs = append(f.connIDs[:i], f.connIDs[i+1:]...)
+			return
+		}
+	}
+}
+
+// restore - restores the file as it was before the first connection and removes the backup
+func (f *resolvConf) restore(filename string) error {
+	if f.existed {
+		if err := writeFile(filename, f.original, f.mode); err != nil {
+			return errors.Wrapf(err, "can't restore %s", filename)
+		}
+		return removeFile(filename + backupSuffix)
+	}
+	if err := removeFile(filename); err != nil {
+		return err
+	}
+	return removeFile(filename + createdSuffix)
+}
+
+func removeFile(filename string) error {
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "can't remove %s", filename)
+	}
+	return nil
+}
+
+// merge - returns the original file with the servers of the connections put before the original ones and the
+// search domains of the connections put before the original ones, the rest of the original file is kept as is
+func (f *resolvConf) merge() []byte {
+	var servers, searchDomains, rest []string
+	for _, connID := range f.connIDs {
+		for _, config := range f.configs[connID] {
+			servers = appendUnique(servers, config.GetDnsServerIps()...)
+			searchDomains = appendUnique(searchDomains, config.GetSearchDomains()...)
+		}
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(f.original))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) > 1 && fields[0] == "nameserver":
+			servers = appendUnique(servers, fields[1])
+		case len(fields) > 1 && (fields[0] == "search" || fields[0] == "domain"):
+			// "domain" and "search" override each other, the last one wins, so both are merged into one search
+			searchDomains = appendUnique(searchDomains, fields[1:]...)
+		default:
+			rest = append(rest, scanner.Text())
+		}
+	}
+	buf := &bytes.Buffer{}
+	for _, server := range servers {
+		_, _ = buf.WriteString("nameserver " + server + "\n")
+	}
+	if len(searchDomains) > 0 {
+		_, _ = buf.WriteString("search " + strings.Join(searchDomains, " ") + "\n")
+	}
+	for _, line := range rest {
+		_, _ = buf.WriteString(line + "\n")
+	}
+	return buf.Bytes()
+}
+
+func appendUnique(values []string, newValues ...string) []string {
+	for _, newValue := range newValues {
+		found := false
+		for _, value := range values {
+			found = found || value == newValue
+		}
+		if !found && newValue != "" {
+			values = append(values, newValue)
+		}
+	}
+	return values
+}
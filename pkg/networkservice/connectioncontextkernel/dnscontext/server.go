@@ -0,0 +1,62 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dnscontext provides networkservice chain elements for applying the DNS context of the connection to the
+// resolver configuration of the workload using the kernel interface
+package dnscontext
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+type dnsContextServer struct {
+	*dnsConfigurator
+}
+
+// NewServer creates a NetworkServiceServer chain element writing the DNS servers and search domains from the
+// DNS context of the connection into the resolv.conf of the workload owning the *kernel* side of an interface
+// plugged into the Endpoint.  The configs of all connections of the workload are merged in front of the original
+// ones, the original resolv.conf is restored when the last of them is closed.  The original is backed up next to
+// the file, so it is restored after a restart too.
+func NewServer(options ...Option) networkservice.NetworkServiceServer {
+	return &dnsContextServer{
+		dnsConfigurator: newDNSConfigurator(options...),
+	}
+}
+
+func (d *dnsContextServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err = d.configure(conn, conn.GetContext().GetDnsContext().GetConfigs()); err != nil {
+		_, _ = next.Server(ctx).Close(ctx, conn)
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (d *dnsContextServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	rv, err := next.Server(ctx).Close(ctx, conn)
+	if configureErr := d.configure(conn, nil); err == nil {
+		err = configureErr
+	}
+	return rv, err
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnscontext_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/dnscontext"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
+)
+
+const originalResolvConf = `nameserver 10.96.0.10
+search default.svc.cluster.local svc.cluster.local
+options ndots:5
+`
+
+func connection(id string, configs ...*networkservice.DNSConfig) *networkservice.Connection {
+	return &networkservice.Connection{
+		Id: id,
+		Mechanism: &networkservice.Mechanism{
+			Type:       kernel.MECHANISM,
+			Parameters: map[string]string{kernel.NetNSURL: "file:///proc/12/ns/net"},
+		},
+		Context: &networkservice.ConnectionContext{
+			DnsContext: &networkservice.DNSContext{Configs: configs},
+		},
+	}
+}
+
+func readFile(t *testing.T, filename string) string {
+	/* #nosec */
+	data, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestDNSContextServerMergeAndRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dnscontext")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	filename := filepath.Join(dir, "resolv.conf")
+	require.NoError(t, ioutil.WriteFile(filename, []byte(originalResolvConf), 0600))
+
+	server := dnscontext.NewServer(dnscontext.WithResolvConfFunc(func(*networkservice.Connection) (string, error) {
+		return filename, nil
+	}))
+	conn1 := connection("1", &networkservice.DNSConfig{
+		DnsServerIps:  []string{"172.16.1.1"},
+		SearchDomains: []string{"nsm-1.local"},
+	})
+	conn2 := connection("2", &networkservice.DNSConfig{
+		DnsServerIps:  []string{"172.16.2.1", "172.16.1.1"},
+		SearchDomains: []string{"nsm-2.local"},
+	})
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn1})
+	require.NoError(t, err)
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn2})
+	require.NoError(t, err)
+	assert.Equal(t, `nameserver 172.16.1.1
+nameserver 172.16.2.1
+nameserver 10.96.0.10
+search nsm-1.local nsm-2.local default.svc.cluster.local svc.cluster.local
+options ndots:5
+`, readFile(t, filename))
+
+	// Refresh without the DNS context drops the configs of the connection
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: connection("1")})
+	require.NoError(t, err)
+	assert.Equal(t, `nameserver 172.16.2.1
+nameserver 172.16.1.1
+nameserver 10.96.0.10
+search nsm-2.local default.svc.cluster.local svc.cluster.local
+options ndots:5
+`, readFile(t, filename))
+
+	_, err = server.Close(context.Background(), conn1)
+	require.NoError(t, err)
+	_, err = server.Close(context.Background(), conn2)
+	require.NoError(t, err)
+	assert.Equal(t, originalResolvConf, readFile(t, filename))
+}
+
+func TestDNSContextServerRemovesCreatedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dnscontext")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	filename := filepath.Join(dir, "resolv.conf")
+
+	server := dnscontext.NewServer(dnscontext.WithResolvConfFunc(func(*networkservice.Connection) (string, error) {
+		return filename, nil
+	}))
+	conn := connection("1", &networkservice.DNSConfig{DnsServerIps: []string{"172.16.1.1"}})
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	assert.Equal(t, "nameserver 172.16.1.1\n", readFile(t, filename))
+
+	_, err = server.Close(context.Background(), conn)
+	require.NoError(t, err)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestDNSContextServerRestoresBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dnscontext")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	filename := filepath.Join(dir, "resolv.conf")
+	// The file configured before a restart and the backup of the original one
+	require.NoError(t, ioutil.WriteFile(filename, []byte("nameserver 172.16.1.1\n"+originalResolvConf), 0600))
+	require.NoError(t, ioutil.WriteFile(filename+".nsm-backup", []byte(originalResolvConf), 0600))
+
+	server := dnscontext.NewServer(dnscontext.WithResolvConfFunc(func(*networkservice.Connection) (string, error) {
+		return filename, nil
+	}))
+	conn := connection("1", &networkservice.DNSConfig{DnsServerIps: []string{"172.16.2.1"}})
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	assert.Equal(t, `nameserver 172.16.2.1
+nameserver 10.96.0.10
+search default.svc.cluster.local svc.cluster.local
+options ndots:5
+`, readFile(t, filename))
+
+	_, err = server.Close(context.Background(), conn)
+	require.NoError(t, err)
+	assert.Equal(t, originalResolvConf, readFile(t, filename))
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestWorkloadResolvConf(t *testing.T) {
+	resolvConf := dnscontext.WorkloadResolvConf(netnsurl.NewResolver())
+	for netNSURL, expected := range map[string]string{
+		"file:///proc/12/ns/net":         "/proc/12/root/etc/resolv.conf",
+		"file:///proc/12/task/13/ns/net": "/proc/12/root/etc/resolv.conf",
+		"pid://12":                       "/proc/12/root/etc/resolv.conf",
+		"netns://blue":                   "/etc/netns/blue/resolv.conf",
+		"file:///var/run/netns/blue":     "/etc/netns/blue/resolv.conf",
+		"file:///run/netns/blue":         "/etc/netns/blue/resolv.conf",
+		// The workload can't be told from these, the DNS context isn't applied
+		"file:///proc/12/fd/3":              "",
+		"file:///var/run/docker/netns/1234": "",
+	} {
+		filename, err := resolvConf(&networkservice.Connection{
+			Mechanism: &networkservice.Mechanism{
+				Type:       kernel.MECHANISM,
+				Parameters: map[string]string{kernel.NetNSURL: netNSURL},
+			},
+		})
+		require.NoError(t, err, netNSURL)
+		assert.Equal(t, expected, filename, netNSURL)
+	}
+}
@@ -32,30 +32,30 @@ import (
 
 type setKernelArpsServer struct{}
 
-// NewServer provides a NetworkServiceServer that sets the arp entry for kernel linux config
+// NewServer provides a NetworkServiceServer that sets the arp (IPv4) and ndp (IPv6) entries for kernel linux config
 func NewServer() networkservice.NetworkServiceServer {
 	return &setKernelArpsServer{}
 }
 
 func (s *setKernelArpsServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	config := vppagent.Config(ctx)
-	iface := kernelctx.ServerInterface(ctx)
-	if iface != nil && request.GetConnection().GetContext().GetEthernetContext().GetDstMac() != "" && request.GetConnection().GetContext().GetIpContext().GetDstIpAddr() != "" {
-		config.GetLinuxConfig().ArpEntries = append(config.GetLinuxConfig().GetArpEntries(),
-			&linux.ARPEntry{
-				IpAddress: strings.Split(request.GetConnection().GetContext().GetIpContext().GetDstIpAddr(), "/")[0],
-				Interface: iface.Name,
-				HwAddress: request.Connection.GetContext().GetEthernetContext().GetDstMac(),
-			},
-		)
-	}
+	s.addArps(ctx, request.GetConnection())
 	return next.Server(ctx).Request(ctx, request)
 }
 
 func (s *setKernelArpsServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	s.addArps(ctx, conn)
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+// addArps - adds static neighbor entries for the dst address and for IpContext.IpNeighbors. For IPv6 addresses they
+// are NDP entries rather than ARP ones, vpp-agent chooses the family from the address.
+func (s *setKernelArpsServer) addArps(ctx context.Context, conn *networkservice.Connection) {
 	config := vppagent.Config(ctx)
 	iface := kernelctx.ServerInterface(ctx)
-	if iface != nil && conn.GetContext().GetEthernetContext().GetDstMac() != "" && conn.GetContext().GetIpContext().GetDstIpAddr() != "" {
+	if iface == nil {
+		return
+	}
+	if conn.GetContext().GetEthernetContext().GetDstMac() != "" && conn.GetContext().GetIpContext().GetDstIpAddr() != "" {
 		config.GetLinuxConfig().ArpEntries = append(config.GetLinuxConfig().GetArpEntries(),
 			&linux.ARPEntry{
 				IpAddress: strings.Split(conn.GetContext().GetIpContext().GetDstIpAddr(), "/")[0],
@@ -64,5 +64,16 @@ func (s *setKernelArpsServer) Close(ctx context.Context, conn *networkservice.Co
 			},
 		)
 	}
-	return next.Server(ctx).Close(ctx, conn)
+	for _, neighbor := range conn.GetContext().GetIpContext().GetIpNeighbors() {
+		if neighbor.GetIp() == "" || neighbor.GetHardwareAddress() == "" {
+			continue
+		}
+		config.GetLinuxConfig().ArpEntries = append(config.GetLinuxConfig().GetArpEntries(),
+			&linux.ARPEntry{
+				IpAddress: strings.Split(neighbor.GetIp(), "/")[0],
+				Interface: iface.Name,
+				HwAddress: neighbor.GetHardwareAddress(),
+			},
+		)
+	}
 }
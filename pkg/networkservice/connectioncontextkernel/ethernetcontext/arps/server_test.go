@@ -53,6 +53,38 @@ func TestClientBasic(t *testing.T) {
 	_, _ = server.Close(context.Background(), request.GetConnection())
 }
 
+func TestServerIPv6Neighbors(t *testing.T) {
+	conn := &networkservice.Connection{
+		Id: "1",
+		Mechanism: &networkservice.Mechanism{
+			Type: kernel.MECHANISM,
+		},
+		Context: &networkservice.ConnectionContext{
+			EthernetContext: &networkservice.EthernetContext{
+				DstMac: "0a:1b:3c:4d:5e:6f",
+			},
+			IpContext: &networkservice.IPContext{
+				DstIpAddr: "fd00::2/64",
+				IpNeighbors: []*networkservice.IpNeighbor{
+					{Ip: "172.16.1.3", HardwareAddress: "0a:1b:3c:4d:5e:70"},
+					{Ip: "fd00::3/64", HardwareAddress: "0a:1b:3c:4d:5e:71"},
+					{Ip: "fd00::4"},
+				},
+			},
+		},
+	}
+	ctx := vppagent.WithConfig(context.Background())
+	iface := &linux.Interface{Name: "server-1"}
+	vppagent.Config(ctx).GetLinuxConfig().Interfaces = []*linux.Interface{iface}
+	_, err := NewServer().Request(kernelctx.WithServerInterface(ctx, iface), &networkservice.NetworkServiceRequest{Connection: conn})
+	assert.Nil(t, err)
+	assert.Equal(t, []*linux.ARPEntry{
+		{Interface: "server-1", IpAddress: "fd00::2", HwAddress: "0a:1b:3c:4d:5e:6f"},
+		{Interface: "server-1", IpAddress: "172.16.1.3", HwAddress: "0a:1b:3c:4d:5e:70"},
+		{Interface: "server-1", IpAddress: "fd00::3", HwAddress: "0a:1b:3c:4d:5e:71"},
+	}, vppagent.Config(ctx).GetLinuxConfig().GetArpEntries())
+}
+
 type testingServer struct {
 	*testing.T
 }
@@ -0,0 +1,59 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package getmac
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsexec"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
+)
+
+// netNSMac - reads the mac address of the single interface in its network namespace, ctx cancels the wait
+func netNSMac(ctx context.Context, iface *linux.Interface) (string, error) {
+	hostIfName := iface.GetHostIfName()
+	if hostIfName == "" {
+		return "", errors.Errorf("no host interface name for interface %s", iface.GetName())
+	}
+	type result struct {
+		mac string
+		err error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		var mac string
+		err := netnsexec.Do(netnsurl.Filename(iface.GetNamespace()), func() error {
+			netIface, err := net.InterfaceByName(hostIfName)
+			if err != nil {
+				return errors.Wrapf(err, "can't find interface %s", hostIfName)
+			}
+			mac = netIface.HardwareAddr.String()
+			return nil
+		})
+		resultCh <- result{mac: mac, err: err}
+	}()
+	select {
+	case r := <-resultCh:
+		return r.mac, r.err
+	case <-ctx.Done():
+		return "", errors.Wrapf(ctx.Err(), "reading the mac address of interface %s", hostIfName)
+	}
+}
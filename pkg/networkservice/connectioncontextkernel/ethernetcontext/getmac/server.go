@@ -24,50 +24,109 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
-	"github.com/sirupsen/logrus"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
 	"google.golang.org/grpc"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
+// MacFunc - returns the mac address of the kernel interface
+type MacFunc func(ctx context.Context, iface *linux.Interface) (string, error)
+
+// Option - option for NewServer
+type Option func(s *getMacKernelServer)
+
+// WithMacFunc - sets the func reading the mac address of the kernel interface, by default it is read from the
+//               network namespace of the interface. The vppagent Dump is used if the func fails.
+func WithMacFunc(macFunc MacFunc) Option {
+	return func(s *getMacKernelServer) {
+		s.macFunc = macFunc
+	}
+}
+
 // NewServer creates a NetworkServiceServer chain element to set the EthernetContext for Kernel connection request
-func NewServer(сс grpc.ClientConnInterface) networkservice.NetworkServiceServer {
-	return &getMacKernelServer{
-		client: configurator.NewConfiguratorServiceClient(сс),
+// The DstMac is set to the mac address of the kernel interface and the IpContext neighbors without a hardware
+// address, the IPv6 peers behind the same interface, get it too.
+func NewServer(сс grpc.ClientConnInterface, options ...Option) networkservice.NetworkServiceServer {
+	s := &getMacKernelServer{
+		client:  configurator.NewConfiguratorServiceClient(сс),
+		macFunc: netNSMac,
+	}
+	for _, opt := range options {
+		opt(s)
 	}
+	return s
 }
 
 type getMacKernelServer struct {
-	client configurator.ConfiguratorServiceClient
+	client  configurator.ConfiguratorServiceClient
+	macFunc MacFunc
 }
 
 func (s *getMacKernelServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
 	var dstInterface *linux.Interface
 	config := vppagent.Config(ctx)
-	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil && len(config.GetLinuxConfig().GetInterfaces()) > 0 {
-		dstInterface = config.LinuxConfig.Interfaces[len(config.LinuxConfig.Interfaces)-1]
+	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil {
+		if dstInterface = kernelctx.ServerInterface(ctx); dstInterface == nil && len(config.GetLinuxConfig().GetInterfaces()) > 0 {
+			dstInterface = config.LinuxConfig.Interfaces[len(config.LinuxConfig.Interfaces)-1]
+		}
 	}
 	conn, err := next.Server(ctx).Request(ctx, request)
-	if err == nil && dstInterface != nil {
-		dump, dumpErr := s.client.Dump(context.Background(), &configurator.DumpRequest{})
-		if dumpErr != nil {
-			logrus.Errorf("An error during ConfiguratorClient.Dump, err: %v", dumpErr.Error())
-			return conn, err
-		}
-		for _, iface := range dump.Dump.LinuxConfig.Interfaces {
-			if iface.Name == dstInterface.Name {
-				request.GetConnection().GetContext().EthernetContext = &networkservice.EthernetContext{
-					DstMac: iface.PhysAddress,
-				}
-				break
-			}
-		}
+	if err != nil || dstInterface == nil {
+		return conn, err
 	}
-	return conn, err
+	mac, macErr := s.getMac(ctx, dstInterface)
+	if macErr != nil {
+		log.Entry(ctx).Errorf("can't get the mac address of interface %s: %v", dstInterface.GetName(), macErr)
+		return conn, nil
+	}
+	setMac(conn, mac)
+	return conn, nil
 }
 
 func (s *getMacKernelServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
 	return next.Server(ctx).Close(ctx, conn)
 }
+
+func (s *getMacKernelServer) getMac(ctx context.Context, iface *linux.Interface) (string, error) {
+	if s.macFunc != nil {
+		mac, err := s.macFunc(ctx, iface)
+		if err == nil {
+			return mac, nil
+		}
+		if ctx.Err() != nil {
+			return "", err
+		}
+		log.Entry(ctx).Warnf("can't read the mac address of interface %s, falling back to vppagent Dump: %v", iface.GetName(), err)
+	}
+	// vpp-agent v3.1.0 can't dump a single interface, so the whole dump is filtered
+	dump, err := s.client.Dump(ctx, &configurator.DumpRequest{})
+	if err != nil {
+		return "", errors.Wrap(err, "error during ConfiguratorClient.Dump")
+	}
+	for _, dumpIface := range dump.GetDump().GetLinuxConfig().GetInterfaces() {
+		if dumpIface.GetName() == iface.GetName() {
+			return dumpIface.GetPhysAddress(), nil
+		}
+	}
+	return "", errors.Errorf("no interface %s in vppagent Dump", iface.GetName())
+}
+
+func setMac(conn *networkservice.Connection, mac string) {
+	if conn.GetContext() == nil {
+		conn.Context = &networkservice.ConnectionContext{}
+	}
+	if conn.GetContext().GetEthernetContext() == nil {
+		conn.GetContext().EthernetContext = &networkservice.EthernetContext{}
+	}
+	conn.GetContext().GetEthernetContext().DstMac = mac
+	for _, neighbor := range conn.GetContext().GetIpContext().GetIpNeighbors() {
+		if neighbor.GetHardwareAddress() == "" {
+			neighbor.HardwareAddress = mac
+		}
+	}
+}
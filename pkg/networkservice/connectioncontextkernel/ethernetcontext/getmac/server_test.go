@@ -19,6 +19,7 @@ package getmac
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
@@ -29,6 +30,7 @@ import (
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
 	"google.golang.org/grpc"
@@ -60,6 +62,73 @@ func TestServerBasic(t *testing.T) {
 	assert.NotNil(t, cc)
 }
 
+func TestServerMacFunc(t *testing.T) {
+	conn := &networkservice.Connection{
+		Id:        "1",
+		Mechanism: &networkservice.Mechanism{Type: kernel.MECHANISM},
+		Context: &networkservice.ConnectionContext{
+			EthernetContext: &networkservice.EthernetContext{SrcMac: "0a:1b:3c:4d:5e:70"},
+			IpContext: &networkservice.IPContext{
+				DstIpAddr: "fd00::2/127",
+				IpNeighbors: []*networkservice.IpNeighbor{
+					{Ip: "fd00::3"},
+					{Ip: "fd00::4", HardwareAddress: "0a:1b:3c:4d:5e:71"},
+				},
+			},
+		},
+	}
+	iface := &linux.Interface{Name: "server-1", HostIfName: "nsm-1"}
+	ctx := kernelctx.WithServerInterface(vppagent.WithConfig(context.Background()), iface)
+	server := &getMacKernelServer{
+		client: &testDumpConfiguratorClient{},
+		macFunc: func(_ context.Context, macIface *linux.Interface) (string, error) {
+			assert.Equal(t, iface, macIface)
+			return "0a:1b:3c:4d:5e:6f", nil
+		},
+	}
+	rv, err := server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	assert.Equal(t, &networkservice.EthernetContext{SrcMac: "0a:1b:3c:4d:5e:70", DstMac: "0a:1b:3c:4d:5e:6f"}, rv.GetContext().GetEthernetContext())
+	assert.Equal(t, "0a:1b:3c:4d:5e:6f", rv.GetContext().GetIpContext().GetIpNeighbors()[0].GetHardwareAddress())
+	assert.Equal(t, "0a:1b:3c:4d:5e:71", rv.GetContext().GetIpContext().GetIpNeighbors()[1].GetHardwareAddress())
+}
+
+func TestServerMacFuncDeadline(t *testing.T) {
+	conn := &networkservice.Connection{
+		Id:        "1",
+		Mechanism: &networkservice.Mechanism{Type: kernel.MECHANISM},
+	}
+	iface := &linux.Interface{Name: "DST-1", HostIfName: "nsm-1"}
+	ctx, cancel := context.WithTimeout(kernelctx.WithServerInterface(vppagent.WithConfig(context.Background()), iface), time.Millisecond)
+	defer cancel()
+	server := &getMacKernelServer{
+		// Dump is not used once the deadline is exceeded
+		client: &testDumpConfiguratorClient{},
+		macFunc: func(macCtx context.Context, _ *linux.Interface) (string, error) {
+			<-macCtx.Done()
+			return "", macCtx.Err()
+		},
+	}
+	rv, err := server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	assert.Empty(t, rv.GetContext().GetEthernetContext().GetDstMac())
+}
+
+func TestServerMacFuncFallback(t *testing.T) {
+	conn := &networkservice.Connection{
+		Id:        "1",
+		Mechanism: &networkservice.Mechanism{Type: kernel.MECHANISM},
+	}
+	// There is no host interface name, so the mac is taken from the vppagent Dump
+	iface := &linux.Interface{Name: "DST-1"}
+	server := NewServer(nil).(*getMacKernelServer)
+	server.client = &testDumpConfiguratorClient{}
+	rv, err := server.Request(kernelctx.WithServerInterface(vppagent.WithConfig(context.Background()), iface),
+		&networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	assert.Equal(t, "0a-1b-3c-4d-5e-6f", rv.GetContext().GetEthernetContext().GetDstMac())
+}
+
 type testDumpConfiguratorClient struct {
 }
 
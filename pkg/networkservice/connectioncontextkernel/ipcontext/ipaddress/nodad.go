@@ -0,0 +1,54 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipaddress
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsexec"
+)
+
+// acceptDADFilename - IPv6 duplicate address detection setting of a single interface in the network namespace of the
+//                     writing thread
+const acceptDADFilename = "/proc/sys/net/ipv6/conf/%s/accept_dad"
+
+// disableDAD - disables IPv6 duplicate address detection on the interface ifaceName of the network namespace referenced
+//              by netnsFilename. vpp-agent can't add addresses with the nodad flag and adds them right after creating
+//              the interface, so the interface only exists once its first address is tentative already: DAD is
+//              disabled for the addresses added to the interface later on, e.g. when vpp-agent resyncs it, while the
+//              first one becomes usable when its DAD completes.
+func disableDAD(netnsFilename, ifaceName string) {
+	err := netnsexec.Do(netnsFilename, func() error {
+		return ioutil.WriteFile(fmt.Sprintf(acceptDADFilename, ifaceName), []byte("0"), 0)
+	})
+	if err != nil {
+		logrus.Warnf("can't disable IPv6 DAD on %s in netns %s: %v", ifaceName, netnsFilename, err)
+	}
+}
+
+// isIPv6 - returns true if ip, with or without a prefix length, is an IPv6 address
+func isIPv6(ip string) bool {
+	addr, _, err := net.ParseCIDR(ip)
+	if err != nil {
+		addr = net.ParseIP(ip)
+	}
+	return addr != nil && addr.To4() == nil
+}
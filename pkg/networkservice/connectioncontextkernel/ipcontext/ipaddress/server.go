@@ -24,7 +24,9 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
 )
 
 type setIPKernelServer struct{}
@@ -63,6 +65,13 @@ func (s *setIPKernelServer) Request(ctx context.Context, request *networkservice
 		if srcIP != "" {
 			iface.IpAddresses = append(iface.GetIpAddresses(), srcIP)
 		}
+		// The interface only exists once the commit down the chain creates it
+		if netnsFilename := netnsurl.Filename(iface.GetNamespace()); isIPv6(srcIP) && netnsFilename != "" {
+			ifaceName := iface.GetHostIfName()
+			vppagent.AfterCommit(ctx, func() {
+				disableDAD(netnsFilename, ifaceName)
+			})
+		}
 	}
 	return next.Server(ctx).Request(ctx, request)
 }
@@ -22,6 +22,7 @@ import (
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
 	linuxl3 "go.ligato.io/vpp-agent/v3/proto/ligato/linux/l3"
@@ -30,7 +31,9 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 )
 
-type setKernelRouteClient struct{}
+type setKernelRouteClient struct {
+	*options
+}
 
 // NewClient creates a NetworkServiceClient that will put the routes from the connection context into
 //  the kernel network namespace kernel interface being inserted iff the
@@ -54,8 +57,11 @@ type setKernelRouteClient struct{}
 //  |                               |         |                           |
 //  +- - - - - - - - - - - - - - - -+         +---------------------------+
 //
-func NewClient() networkservice.NetworkServiceClient {
-	return &setKernelRouteClient{}
+// Routes overlapping IpContext.ExcludedPrefixes or shadowing the routes already present in the kernel network
+// namespace fail the Request unless WithSkipConflicts is set. Policy routes are not supported, vpp-agent v3.1.0 has
+// neither linux rules nor route tables.
+func NewClient(options ...Option) networkservice.NetworkServiceClient {
+	return &setKernelRouteClient{options: newOptions(options...)}
 }
 
 func (s *setKernelRouteClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
@@ -63,8 +69,11 @@ func (s *setKernelRouteClient) Request(ctx context.Context, request *networkserv
 	if err != nil {
 		return nil, err
 	}
-	s.addRoutes(ctx, rv)
-	return rv, err
+	if err = s.addRoutes(ctx, rv, true); err != nil {
+		_, _ = next.Client(ctx).Close(ctx, rv, opts...)
+		return nil, err
+	}
+	return rv, nil
 }
 
 func (s *setKernelRouteClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
@@ -72,26 +81,35 @@ func (s *setKernelRouteClient) Close(ctx context.Context, conn *networkservice.C
 	if err != nil {
 		return nil, err
 	}
-	s.addRoutes(ctx, conn)
+	_ = s.addRoutes(ctx, conn, false)
 	return rv, err
 }
 
-func (s *setKernelRouteClient) addRoutes(ctx context.Context, conn *networkservice.Connection) {
-	if conn.GetContext().GetIpContext().GetSrcIpAddr() == "" {
-		return
-	}
-	srcIP, srcNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetSrcIpAddr())
-	if err != nil {
-		return
-	}
+func (s *setKernelRouteClient) addRoutes(ctx context.Context, conn *networkservice.Connection, check bool) error {
 	conf := vppagent.Config(ctx)
 	index := len(conf.GetLinuxConfig().GetInterfaces()) - 1
-	if index >= 0 && srcIP.IsGlobalUnicast() {
-		iface := conf.GetLinuxConfig().GetInterfaces()[index]
-		vppagent.Config(ctx).GetLinuxConfig().Routes = append(vppagent.Config(ctx).GetLinuxConfig().Routes, &linux.Route{
+	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism == nil || index < 0 {
+		return nil
+	}
+	// The kernel mechanism client appends its interface after the Request returns, so it is the last one
+	iface := conf.GetLinuxConfig().GetInterfaces()[index]
+	ipContext := conn.GetContext().GetIpContext()
+	if check {
+		warnUnknownFields(conn.GetId(), ipContext)
+	}
+	linuxConfig := conf.GetLinuxConfig()
+	start := len(linuxConfig.GetRoutes())
+	srcIP, srcNet, err := net.ParseCIDR(ipContext.GetSrcIpAddr())
+	if err == nil && srcIP.IsGlobalUnicast() {
+		linuxConfig.Routes = append(linuxConfig.Routes, &linux.Route{
 			DstNetwork:        srcNet.String(),
 			OutgoingInterface: iface.GetName(),
 			Scope:             linuxl3.Route_LINK,
 		})
 	}
+	appendRoutes(linuxConfig, iface.GetName(), ipContext.GetDstRoutes(), extractCleanIPAddress(ipContext.GetSrcIpAddr()))
+	if !check {
+		return nil
+	}
+	return s.filterRoutes(ctx, iface, ipContext.GetExcludedPrefixes(), linuxConfig, start)
 }
@@ -0,0 +1,84 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	linuxl3 "go.ligato.io/vpp-agent/v3/proto/ligato/linux/l3"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/routes"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func TestKernelRoutesClientDstRoutes(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	client := chain.NewNetworkServiceClient(
+		routes.NewClient(routes.WithRoutesFunc(noRoutes)),
+		kerneltap.NewClient(),
+	)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+				Parameters: map[string]string{
+					kernel.NetNSURL: (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+				},
+			},
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "172.16.1.1/30",
+					DstIpAddr: "172.16.1.2/30",
+					DstRoutes: []*networkservice.Route{
+						{Prefix: "10.0.0.0/8"},
+						{Prefix: "10.0.0.0/8"},
+						{Prefix: "fd02::/64"},
+					},
+				},
+			},
+		},
+	}
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := client.Request(ctx, request)
+	require.NoError(t, err)
+
+	linuxRoutes := vppagent.Config(ctx).GetLinuxConfig().GetRoutes()
+	require.Len(t, linuxRoutes, 3)
+	for _, linuxRoute := range linuxRoutes {
+		assert.Equal(t, "client-1", linuxRoute.GetOutgoingInterface())
+	}
+	assert.Equal(t, "172.16.1.0/30", linuxRoutes[0].GetDstNetwork())
+	assert.Equal(t, linuxl3.Route_LINK, linuxRoutes[0].GetScope())
+	assert.Equal(t, "10.0.0.0/8", linuxRoutes[1].GetDstNetwork())
+	assert.Equal(t, linuxl3.Route_GLOBAL, linuxRoutes[1].GetScope())
+	assert.Equal(t, "172.16.1.1", linuxRoutes[1].GetGwAddr())
+	assert.Equal(t, "fd02::/64", linuxRoutes[2].GetDstNetwork())
+	assert.Equal(t, linuxl3.Route_LINK, linuxRoutes[2].GetScope())
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes
+
+import (
+	"net"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/sirupsen/logrus"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	linuxl3 "go.ligato.io/vpp-agent/v3/proto/ligato/linux/l3"
+)
+
+// appendRoutes - appends the routes to the prefixes via gwAddr over the interface ifaceName and returns the set of
+// the prefixes. The gateway can be used only for the routes of its own family, the routes of the other family
+// of a dual-stack connection go directly over the link.
+func appendRoutes(linuxConfig *linux.ConfigData, ifaceName string, routes []*networkservice.Route, gwAddr string) map[string]bool {
+	prefixes := make(map[string]bool)
+	for _, route := range routes {
+		if _, ok := prefixes[route.GetPrefix()]; ok {
+			continue
+		}
+		prefixes[route.GetPrefix()] = true
+		linuxRoute := &linux.Route{
+			DstNetwork:        route.GetPrefix(),
+			OutgoingInterface: ifaceName,
+			Scope:             linuxl3.Route_GLOBAL,
+			GwAddr:            gwAddr,
+		}
+		if gwAddr == "" || !sameFamily(route.GetPrefix(), gwAddr) {
+			linuxRoute.Scope = linuxl3.Route_LINK
+			linuxRoute.GwAddr = ""
+		}
+		linuxConfig.Routes = append(linuxConfig.Routes, linuxRoute)
+	}
+	return prefixes
+}
+
+// sameFamily - returns false only if both prefix and addr are valid and one is IPv4 while the other is IPv6
+func sameFamily(prefix, addr string) bool {
+	prefixIP := net.ParseIP(extractCleanIPAddress(prefix))
+	ip := net.ParseIP(addr)
+	if prefixIP == nil || ip == nil {
+		return true
+	}
+	return (prefixIP.To4() == nil) == (ip.To4() == nil)
+}
+
+func extractCleanIPAddress(addr string) string {
+	ip, _, err := net.ParseCIDR(addr)
+	if err == nil {
+		return ip.String()
+	}
+	return addr
+}
+
+// warnUnknownFields - warns if ipContext carries fields unknown to this api version, like the policy routes of the
+// newer ones: vpp-agent v3.1.0 has neither linux rules nor route tables, so they would be silently ignored otherwise
+func warnUnknownFields(connID string, ipContext *networkservice.IPContext) {
+	if ipContext != nil && len(proto.MessageReflect(ipContext).GetUnknown()) > 0 {
+		logrus.Warnf("the IP context of the connection %s has fields which are not supported, e.g. policy routes, "+
+			"they are ignored", connID)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes
+
+import (
+	"context"
+	"net"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsroutes"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
+)
+
+// Option - option for NewClient and NewServer
+type Option func(o *options)
+
+type options struct {
+	skipConflicts bool
+	listRoutes    netnsroutes.Func
+}
+
+// WithSkipConflicts - conflicting routes are skipped with a warning instead of failing the Request
+func WithSkipConflicts() Option {
+	return func(o *options) {
+		o.skipConflicts = true
+	}
+}
+
+// WithRoutesFunc - sets the func listing the routes already present in the target netns,
+//                  nil disables the check against the existing routes leaving only the excluded prefixes check
+func WithRoutesFunc(listRoutes netnsroutes.Func) Option {
+	return func(o *options) {
+		o.listRoutes = listRoutes
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		listRoutes: netnsroutes.List,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// filterRoutes - checks the routes appended to the linuxConfig after the start index, the conflicting ones are removed
+func (o *options) filterRoutes(ctx context.Context, iface *linux.Interface, excludedPrefixes []string, linuxConfig *linux.ConfigData, start int) error {
+	routes, err := o.checkConflicts(ctx, iface, excludedPrefixes, linuxConfig.GetRoutes()[start:])
+	if err != nil {
+		linuxConfig.Routes = linuxConfig.Routes[:start]
+		return err
+	}
+	linuxConfig.Routes = append(linuxConfig.Routes[:start], routes...)
+	return nil
+}
+
+// checkConflicts - returns routes without the ones overlapping the excluded prefixes or shadowing the routes already
+// present in the netns of the iface, fails on the first conflict unless the conflicts are skipped.
+// Only the excluded prefixes are checked if the routes of the netns can't be listed
+func (o *options) checkConflicts(ctx context.Context, iface *linux.Interface, excludedPrefixes []string, routes []*linux.Route) ([]*linux.Route, error) {
+	var excluded []*net.IPNet
+	for _, prefix := range excludedPrefixes {
+		_, ipNet, err := net.ParseCIDR(prefix)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid excluded prefix %s", prefix)
+		}
+		excluded = append(excluded, ipNet)
+	}
+	var existing []*netnsroutes.Route
+	if o.listRoutes != nil {
+		netnsFilename := netnsurl.Filename(iface.GetNamespace())
+		all, err := o.listRoutes(netnsFilename)
+		if err != nil {
+			// The excluded prefixes are what the connection context requires, the routes of the netns can change at any
+			// time after the check anyway: not knowing them only loses the protection against shadowing
+			log.Entry(ctx).Warnf("can't list routes in netns %q, checking only the excluded prefixes: %v", netnsFilename, err)
+		}
+		for _, route := range all {
+			// Our own routes are there on refresh
+			if route.Interface != iface.GetHostIfName() {
+				existing = append(existing, route)
+			}
+		}
+	}
+	var rv []*linux.Route
+	for _, route := range routes {
+		err := conflict(route, excluded, existing)
+		if err == nil {
+			rv = append(rv, route)
+			continue
+		}
+		if !o.skipConflicts {
+			return nil, err
+		}
+		log.Entry(ctx).Warnf("skipping route: %v", err)
+	}
+	return rv, nil
+}
+
+func conflict(route *linux.Route, excluded []*net.IPNet, existing []*netnsroutes.Route) error {
+	_, dst, err := net.ParseCIDR(route.GetDstNetwork())
+	if err != nil {
+		return errors.Wrapf(err, "invalid route prefix %s", route.GetDstNetwork())
+	}
+	for _, prefix := range excluded {
+		if prefix.Contains(dst.IP) || dst.Contains(prefix.IP) {
+			return errors.Errorf("route %s dev %s overlaps excluded prefix %s", dst, route.GetOutgoingInterface(), prefix)
+		}
+	}
+	dstOnes, dstBits := dst.Mask.Size()
+	for _, existingRoute := range existing {
+		ones, bits := existingRoute.Dst.Mask.Size()
+		if bits == dstBits && ones <= dstOnes && existingRoute.Dst.Contains(dst.IP) {
+			return errors.Errorf("route %s dev %s shadows existing route %s", dst, route.GetOutgoingInterface(), existingRoute)
+		}
+	}
+	return nil
+}
@@ -33,7 +33,9 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
-type setKernelRoute struct{}
+type setKernelRoute struct {
+	*options
+}
 
 // NewServer creates a NetworkServiceServer that will put the routes from the connection context into
 //  connection context into the kernel network namespace kernel interface being inserted iff the
@@ -57,59 +59,44 @@ type setKernelRoute struct{}
 //  |                               |         |                           |
 //  +- - - - - - - - - - - - - - - -+         +---------------------------+
 //
-func NewServer() networkservice.NetworkServiceServer {
-	return &setKernelRoute{}
+// Routes overlapping IpContext.ExcludedPrefixes or shadowing the routes already present in the kernel network
+// namespace fail the Request unless WithSkipConflicts is set.
+func NewServer(options ...Option) networkservice.NetworkServiceServer {
+	return &setKernelRoute{options: newOptions(options...)}
 }
 
 func (s *setKernelRoute) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	s.addRoutes(ctx, request.GetConnection())
+	if err := s.addRoutes(ctx, request.GetConnection(), true); err != nil {
+		return nil, err
+	}
 	return next.Server(ctx).Request(ctx, request)
 }
 
 func (s *setKernelRoute) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
-	s.addRoutes(ctx, conn)
+	_ = s.addRoutes(ctx, conn, false)
 	return next.Server(ctx).Close(ctx, conn)
 }
 
-func (s *setKernelRoute) addRoutes(ctx context.Context, conn *networkservice.Connection) {
-	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		duplicatedPrefixes := make(map[string]bool)
-		for _, route := range conn.GetContext().GetIpContext().GetSrcRoutes() {
-			if _, ok := duplicatedPrefixes[route.Prefix]; !ok {
-				duplicatedPrefixes[route.Prefix] = true
-				vppagent.Config(ctx).GetLinuxConfig().Routes = append(vppagent.Config(ctx).GetLinuxConfig().Routes, &linux.Route{
-					DstNetwork:        route.Prefix,
-					OutgoingInterface: vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetName(),
-					Scope:             linuxl3.Route_GLOBAL,
-					GwAddr:            extractCleanIPAddress(conn.GetContext().GetIpContext().GetDstIpAddr()),
-				})
-			}
-		}
-		_, srcNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetSrcIpAddr())
-		if err != nil {
-			return
-		}
-		dstIP, dstNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetDstIpAddr())
-		if err != nil {
-			return
-		}
-		if _, ok := duplicatedPrefixes[dstNet.String()]; ok || srcNet.Contains(dstIP) {
-			return
-		}
-		if iface := kernelctx.ServerInterface(ctx); iface != nil && dstIP.IsGlobalUnicast() {
-			vppagent.Config(ctx).GetLinuxConfig().Routes = append(vppagent.Config(ctx).GetLinuxConfig().Routes, &linux.Route{
-				DstNetwork:        dstNet.String(),
-				OutgoingInterface: iface.GetName(),
-				Scope:             linuxl3.Route_LINK,
-			})
-		}
+func (s *setKernelRoute) addRoutes(ctx context.Context, conn *networkservice.Connection, check bool) error {
+	iface := kernelctx.ServerInterface(ctx)
+	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism == nil || iface == nil {
+		return nil
 	}
-}
-
-func extractCleanIPAddress(addr string) string {
-	ip, _, err := net.ParseCIDR(addr)
-	if err == nil {
-		return ip.String()
+	ipContext := conn.GetContext().GetIpContext()
+	linuxConfig := vppagent.Config(ctx).GetLinuxConfig()
+	start := len(linuxConfig.GetRoutes())
+	duplicatedPrefixes := appendRoutes(linuxConfig, iface.GetName(), ipContext.GetSrcRoutes(), extractCleanIPAddress(ipContext.GetDstIpAddr()))
+	_, srcNet, srcErr := net.ParseCIDR(ipContext.GetSrcIpAddr())
+	dstIP, dstNet, dstErr := net.ParseCIDR(ipContext.GetDstIpAddr())
+	if srcErr == nil && dstErr == nil && !duplicatedPrefixes[dstNet.String()] && !srcNet.Contains(dstIP) && dstIP.IsGlobalUnicast() {
+		linuxConfig.Routes = append(linuxConfig.Routes, &linux.Route{
+			DstNetwork:        dstNet.String(),
+			OutgoingInterface: iface.GetName(),
+			Scope:             linuxl3.Route_LINK,
+		})
+	}
+	if !check {
+		return nil
 	}
-	return addr
+	return s.filterRoutes(ctx, iface, ipContext.GetExcludedPrefixes(), linuxConfig, start)
 }
@@ -0,0 +1,221 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	linuxl3 "go.ligato.io/vpp-agent/v3/proto/ligato/linux/l3"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/routes"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsroutes"
+)
+
+const (
+	netnsFileURL = "/proc/12/ns/net"
+)
+
+func noRoutes(string) ([]*netnsroutes.Route, error) {
+	return nil, nil
+}
+
+func serverRequest(ipContext *networkservice.IPContext) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+				Parameters: map[string]string{
+					kernel.NetNSURL: (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+				},
+			},
+			Context: &networkservice.ConnectionContext{
+				IpContext: ipContext,
+			},
+		},
+	}
+}
+
+func requestRoutes(t *testing.T, ipContext *networkservice.IPContext) []*linux.Route {
+	server := chain.NewNetworkServiceServer(
+		kerneltap.NewServer(),
+		routes.NewServer(routes.WithRoutesFunc(noRoutes)),
+	)
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, serverRequest(ipContext))
+	require.NoError(t, err)
+	return vppagent.Config(ctx).GetLinuxConfig().GetRoutes()
+}
+
+func TestKernelRoutesServerIPv6(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	linuxRoutes := requestRoutes(t, &networkservice.IPContext{
+		SrcIpAddr: "fd00::1/127",
+		DstIpAddr: "fd00:1::1/127",
+		SrcRoutes: []*networkservice.Route{
+			{Prefix: "fd01::/64"},
+		},
+	})
+	require.Len(t, linuxRoutes, 2)
+	assert.Equal(t, "fd01::/64", linuxRoutes[0].GetDstNetwork())
+	assert.Equal(t, linuxl3.Route_GLOBAL, linuxRoutes[0].GetScope())
+	assert.Equal(t, "fd00:1::1", linuxRoutes[0].GetGwAddr())
+	assert.Equal(t, "fd00:1::/127", linuxRoutes[1].GetDstNetwork())
+	assert.Equal(t, linuxl3.Route_LINK, linuxRoutes[1].GetScope())
+}
+
+func TestKernelRoutesServerDualStack(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	linuxRoutes := requestRoutes(t, &networkservice.IPContext{
+		SrcIpAddr: "172.16.1.1/30",
+		DstIpAddr: "172.16.1.2/30",
+		SrcRoutes: []*networkservice.Route{
+			{Prefix: "10.0.0.0/8"},
+			{Prefix: "fd01::/64"},
+		},
+	})
+	require.Len(t, linuxRoutes, 2)
+	assert.Equal(t, "10.0.0.0/8", linuxRoutes[0].GetDstNetwork())
+	assert.Equal(t, linuxl3.Route_GLOBAL, linuxRoutes[0].GetScope())
+	assert.Equal(t, "172.16.1.2", linuxRoutes[0].GetGwAddr())
+	// There is no IPv6 gateway, so the IPv6 route goes directly over the link
+	assert.Equal(t, "fd01::/64", linuxRoutes[1].GetDstNetwork())
+	assert.Equal(t, linuxl3.Route_LINK, linuxRoutes[1].GetScope())
+	assert.Empty(t, linuxRoutes[1].GetGwAddr())
+}
+
+func TestKernelRoutesServerUsesServerInterface(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		kerneltap.NewServer(),
+		routes.NewServer(routes.WithRoutesFunc(noRoutes)),
+	)
+	ctx := vppagent.WithConfig(context.Background())
+	// An interface of another element is already in the config
+	vppagent.Config(ctx).GetLinuxConfig().Interfaces = []*linux.Interface{{Name: "client-2"}}
+	_, err := server.Request(ctx, serverRequest(&networkservice.IPContext{
+		SrcIpAddr: "172.16.1.1/30",
+		DstIpAddr: "172.16.1.2/30",
+		SrcRoutes: []*networkservice.Route{
+			{Prefix: "10.0.0.0/8"},
+		},
+	}))
+	require.NoError(t, err)
+	linuxRoutes := vppagent.Config(ctx).GetLinuxConfig().GetRoutes()
+	require.Len(t, linuxRoutes, 1)
+	assert.Equal(t, "server-1", linuxRoutes[0].GetOutgoingInterface())
+}
+
+func TestKernelRoutesServerConflicts(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	var netnsFilename string
+	existingRoutes := func(filename string) ([]*netnsroutes.Route, error) {
+		netnsFilename = filename
+		_, clusterNet, _ := net.ParseCIDR("10.244.0.0/16")
+		_, ownNet, _ := net.ParseCIDR("192.168.0.0/16")
+		return []*netnsroutes.Route{
+			{Dst: clusterNet, Interface: "eth0"},
+			// Routes via the NSM interface itself are there on refresh
+			{Dst: ownNet, Interface: "nsm-1"},
+		}, nil
+	}
+	ipContext := &networkservice.IPContext{
+		SrcIpAddr:        "172.16.1.1/30",
+		DstIpAddr:        "172.16.1.2/30",
+		ExcludedPrefixes: []string{"10.96.0.0/12"},
+		SrcRoutes: []*networkservice.Route{
+			{Prefix: "10.244.1.0/24"},
+			{Prefix: "10.100.0.0/16"},
+			{Prefix: "192.168.1.0/24"},
+			{Prefix: "10.0.0.0/8"},
+		},
+	}
+
+	request := serverRequest(ipContext)
+	request.GetConnection().GetMechanism().GetParameters()[kernel.InterfaceNameKey] = "nsm-1"
+
+	ctx := vppagent.WithConfig(context.Background())
+	server := chain.NewNetworkServiceServer(kerneltap.NewServer(), routes.NewServer(routes.WithRoutesFunc(existingRoutes)))
+	_, err := server.Request(ctx, request)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "route 10.244.1.0/24 dev server-1 shadows existing route 10.244.0.0/16 dev eth0")
+	assert.Equal(t, netnsFileURL, netnsFilename)
+	assert.Empty(t, vppagent.Config(ctx).GetLinuxConfig().GetRoutes())
+
+	ctx = vppagent.WithConfig(context.Background())
+	server = chain.NewNetworkServiceServer(kerneltap.NewServer(), routes.NewServer(
+		routes.WithRoutesFunc(existingRoutes),
+		routes.WithSkipConflicts(),
+	))
+	_, err = server.Request(ctx, request)
+	require.NoError(t, err)
+	var dstNetworks []string
+	for _, linuxRoute := range vppagent.Config(ctx).GetLinuxConfig().GetRoutes() {
+		dstNetworks = append(dstNetworks, linuxRoute.GetDstNetwork())
+	}
+	// 10.100.0.0/16 is inside of the excluded 10.96.0.0/12, 10.0.0.0/8 contains it
+	assert.Equal(t, []string{"192.168.1.0/24"}, dstNetworks)
+}
+
+func TestKernelRoutesServerUnlistableNetNS(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	failingRoutes := func(string) ([]*netnsroutes.Route, error) {
+		return nil, errors.New("can't enter netns")
+	}
+	ipContext := &networkservice.IPContext{
+		SrcIpAddr:        "172.16.1.1/30",
+		DstIpAddr:        "172.16.1.2/30",
+		ExcludedPrefixes: []string{"10.96.0.0/12"},
+		SrcRoutes: []*networkservice.Route{
+			{Prefix: "10.100.0.0/16"},
+			{Prefix: "192.168.1.0/24"},
+		},
+	}
+
+	ctx := vppagent.WithConfig(context.Background())
+	server := chain.NewNetworkServiceServer(kerneltap.NewServer(), routes.NewServer(routes.WithRoutesFunc(failingRoutes)))
+	_, err := server.Request(ctx, serverRequest(ipContext))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "overlaps excluded prefix 10.96.0.0/12")
+
+	ctx = vppagent.WithConfig(context.Background())
+	server = chain.NewNetworkServiceServer(kerneltap.NewServer(), routes.NewServer(
+		routes.WithRoutesFunc(failingRoutes),
+		routes.WithSkipConflicts(),
+	))
+	_, err = server.Request(ctx, serverRequest(ipContext))
+	require.NoError(t, err)
+	linuxRoutes := vppagent.Config(ctx).GetLinuxConfig().GetRoutes()
+	require.Len(t, linuxRoutes, 1)
+	assert.Equal(t, "192.168.1.0/24", linuxRoutes[0].GetDstNetwork())
+}
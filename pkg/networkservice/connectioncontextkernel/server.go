@@ -21,6 +21,8 @@ import (
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/macgen"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/dnscontext"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ethernetcontext/macaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/ipaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/routes"
@@ -51,9 +53,11 @@ import (
 //
 func NewServer() networkservice.NetworkServiceServer {
 	return chain.NewNetworkServiceServer(
+		macgen.NewServer(),
 		ipaddress.NewServer(),
 		macaddress.NewServer(),
 		// Note: routes are only applicable in this circumstance in the server side
 		routes.NewServer(),
+		dnscontext.NewServer(),
 	)
 }
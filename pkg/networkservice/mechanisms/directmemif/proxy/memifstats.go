@@ -0,0 +1,154 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package proxy
+
+import (
+	"encoding/binary"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// memif control protocol and shared memory layout, see vpp/src/plugins/memif/memif.h
+const (
+	msgTypeAddRegion = 4
+	msgTypeAddRing   = 5
+
+	addRegionMsgSize = 8
+	addRingMsgSize   = 13
+	addRingFlagS2M   = 1
+
+	ringHeadOffset = 6
+	ringTailOffset = 64
+	ringDescOffset = 128
+	descSize       = 16
+	descFlagNext   = 1
+)
+
+// memifRing is a ring announced by the memif slave over the control channel
+type memifRing struct {
+	s2m    bool
+	region uint16
+	offset uint32
+	size   uint32
+	last   uint16
+}
+
+// memifStats reads packet and byte counters from the memif rings shared between the endpoints
+type memifStats struct {
+	regions map[uint16][]byte
+	rings   []*memifRing
+}
+
+func newMemifStats() *memifStats {
+	return &memifStats{
+		regions: map[uint16][]byte{},
+	}
+}
+
+// handleMessage inspects a control message and maps the regions and rings it announces
+func (s *memifStats) handleMessage(data []byte, fds []int) {
+	if len(data) < 2 {
+		return
+	}
+	switch binary.LittleEndian.Uint16(data) {
+	case msgTypeAddRegion:
+		if len(data) < addRegionMsgSize || len(fds) == 0 {
+			return
+		}
+		index := binary.LittleEndian.Uint16(data[2:])
+		size := binary.LittleEndian.Uint32(data[4:])
+		mem, err := syscall.Mmap(fds[0], 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+		if err != nil {
+			logrus.Errorf("can't map memif region %v: %v", index, err)
+			return
+		}
+		if prev, ok := s.regions[index]; ok {
+			_ = syscall.Munmap(prev)
+		}
+		s.regions[index] = mem
+	case msgTypeAddRing:
+		if len(data) < addRingMsgSize {
+			return
+		}
+		s.rings = append(s.rings, &memifRing{
+			s2m:    binary.LittleEndian.Uint16(data[2:])&addRingFlagS2M != 0,
+			region: binary.LittleEndian.Uint16(data[6:]),
+			offset: binary.LittleEndian.Uint32(data[8:]),
+			size:   1 << data[12],
+		})
+	}
+}
+
+// collect counts the descriptors produced since the previous call. For S2M rings the slave is the producer
+// and moves the head, for M2S rings the master fills buffers and moves the tail.
+func (s *memifStats) collect(metrics map[string]uint) {
+	for _, ring := range s.rings {
+		mem := s.regions[ring.region]
+		descEnd := uint64(ring.offset) + ringDescOffset + uint64(ring.size)*descSize
+		if mem == nil || descEnd > uint64(len(mem)) {
+			continue
+		}
+		header := mem[ring.offset:]
+		packetsKey, bytesKey := rxPackets, rxBytes
+		current := binary.LittleEndian.Uint16(header[ringTailOffset:])
+		if ring.s2m {
+			packetsKey, bytesKey = txPackets, txBytes
+			current = binary.LittleEndian.Uint16(header[ringHeadOffset:])
+		}
+		produced := uint32(current - ring.last)
+		if produced > ring.size {
+			// The ring has wrapped since the last poll, descriptors of the lost slots are already overwritten
+			metrics[packetsKey] += uint(produced - ring.size)
+			ring.last += uint16(produced - ring.size)
+		}
+		for ; ring.last != current; ring.last++ {
+			desc := header[ringDescOffset+uint32(ring.last&uint16(ring.size-1))*descSize:]
+			metrics[bytesKey] += uint(binary.LittleEndian.Uint32(desc[4:]))
+			if binary.LittleEndian.Uint16(desc)&descFlagNext == 0 {
+				metrics[packetsKey]++
+			}
+		}
+	}
+}
+
+// release unmaps all the regions
+func (s *memifStats) release() {
+	for index, mem := range s.regions {
+		_ = syscall.Munmap(mem)
+		delete(s.regions, index)
+	}
+	s.rings = nil
+}
+
+func parseRights(cmsg []byte) []int {
+	msgs, err := syscall.ParseSocketControlMessage(cmsg)
+	if err != nil {
+		return nil
+	}
+	var fds []int
+	for i := range msgs {
+		rights, err := syscall.ParseUnixRights(&msgs[i])
+		if err != nil {
+			continue
+		}
+		fds = append(fds, rights...)
+	}
+	return fds
+}
@@ -25,6 +25,7 @@ import (
 	"os"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -35,9 +36,14 @@ const (
 	// RxBytes is a total number of bytes received from target
 	rxBytes = "rx_bytes"
 	// TxBytes is a total number of bytes transmitted to target
-	txBytes    = "tx_bytes"
-	bufferSize = 128
-	cmsgSize   = 24
+	txBytes = "tx_bytes"
+	// RxPackets is a total number of packets received from target
+	rxPackets = "rx_packets"
+	// TxPackets is a total number of packets transmitted to target
+	txPackets         = "tx_packets"
+	bufferSize        = 128
+	cmsgSize          = 24
+	statsPollInterval = 100 * time.Millisecond
 )
 
 // StopListenerAdapter adapts func() to Listener interface
@@ -72,6 +78,7 @@ type proxyImpl struct {
 	source         *net.UnixAddr
 	target         *net.UnixAddr
 	metrics        map[string]uint
+	stats          *memifStats
 }
 
 type connectionResult struct {
@@ -103,9 +110,12 @@ func New(sourceSocket, targetSocket, network string, listener Listener) (Proxy,
 		network:  network,
 		listener: listener,
 		metrics: map[string]uint{
-			rxBytes: 0,
-			txBytes: 0,
+			rxBytes:   0,
+			txBytes:   0,
+			rxPackets: 0,
+			txPackets: 0,
 		},
+		stats: newMemifStats(),
 	}, nil
 }
 
@@ -155,8 +165,10 @@ func (p *proxyImpl) Stop() error {
 
 // Metrics returns direct memif metrics' map representation
 func (p *proxyImpl) Metrics() map[string]string {
-	p.lock.RLock()
-	defer p.lock.RUnlock()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.stats.collect(p.metrics)
 
 	result := make(map[string]string)
 	for k, v := range p.metrics {
@@ -209,19 +221,48 @@ func (p *proxyImpl) proxy() error {
 	sourceStopCh := make(chan struct{})
 	targetStopCh := make(chan struct{})
 
-	go p.transfer(sourceFd, targetFd, sourceStopCh, txBytes)
-	go p.transfer(targetFd, sourceFd, targetStopCh, rxBytes)
+	var transferWg sync.WaitGroup
+	transferWg.Add(2)
+	go func() {
+		defer transferWg.Done()
+		p.transfer(sourceFd, targetFd, sourceStopCh)
+	}()
+	go func() {
+		defer transferWg.Done()
+		p.transfer(targetFd, sourceFd, targetStopCh)
+	}()
 
-	select {
-	case <-p.stopCh:
-		break
-	case <-sourceStopCh:
-		break
-	case <-targetStopCh:
-		break
+	ticker := time.NewTicker(statsPollInterval)
+	defer ticker.Stop()
+	// Regions are released only after both transfers have returned, so no message can map new ones afterwards
+	defer p.releaseStats()
+	defer transferWg.Wait()
+	defer shutdownFds(sourceFd, targetFd)
+	for {
+		select {
+		case <-ticker.C:
+			p.collectStats()
+			continue
+		case <-p.stopCh:
+		case <-sourceStopCh:
+		case <-targetStopCh:
+		}
+		logrus.Info("Proxy has been stopped")
+		return nil
 	}
-	logrus.Info("Proxy has been stopped")
-	return nil
+}
+
+func (p *proxyImpl) collectStats() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.stats.collect(p.metrics)
+}
+
+func (p *proxyImpl) releaseStats() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.stats.collect(p.metrics)
+	p.stats.release()
 }
 
 func connectToTargetAsync(target *net.UnixAddr, network string, stopCh <-chan struct{}) (*net.UnixConn, error) {
@@ -272,7 +313,7 @@ func acceptConnectionAsync(listener *net.UnixListener, stopCh <-chan struct{}) (
 	}
 }
 
-func (p *proxyImpl) transfer(fromFd, toFd int, stopCh chan struct{}, metricsKey string) {
+func (p *proxyImpl) transfer(fromFd, toFd int, stopCh chan struct{}) {
 	dataBuffer := make([]byte, bufferSize)
 	cmsgBuffer := make([]byte, cmsgSize)
 	defer close(stopCh)
@@ -288,6 +329,10 @@ func (p *proxyImpl) transfer(fromFd, toFd int, stopCh chan struct{}, metricsKey
 				return
 			}
 			logrus.Infof("Received message from %v", fromFd)
+			fds := parseRights(cmsgBuffer[:cmsgN])
+			p.lock.Lock()
+			p.stats.handleMessage(dataBuffer[:dataN], fds)
+			p.lock.Unlock()
 			var sendDataBuf []byte = nil
 			if dataN > 0 {
 				sendDataBuf = dataBuffer
@@ -296,19 +341,29 @@ func (p *proxyImpl) transfer(fromFd, toFd int, stopCh chan struct{}, metricsKey
 			if cmsgN > 0 {
 				sendCmsgBuf = cmsgBuffer
 			}
-			if err := syscall.Sendmsg(toFd, sendDataBuf, sendCmsgBuf, nil, 0); err != nil {
+			err = syscall.Sendmsg(toFd, sendDataBuf, sendCmsgBuf, nil, 0)
+			closeFds(fds)
+			if err != nil {
 				logrus.Error(err)
 				return
 			}
 			logrus.Infof("Send message to %v", toFd)
-
-			p.lock.Lock()
-			p.metrics[metricsKey] += uint(dataN)
-			p.lock.Unlock()
 		}
 	}
 }
 
+func shutdownFds(fds ...int) {
+	for _, fd := range fds {
+		_ = syscall.Shutdown(fd, syscall.SHUT_RDWR)
+	}
+}
+
+func closeFds(fds []int) {
+	for _, fd := range fds {
+		_ = syscall.Close(fd)
+	}
+}
+
 func getConnFd(conn *net.UnixConn) (fd int, closeFunc func(), err error) {
 	file, err := conn.File()
 	if err != nil {
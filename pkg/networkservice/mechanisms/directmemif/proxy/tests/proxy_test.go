@@ -17,9 +17,13 @@
 package tests
 
 import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
 	"net"
 	"os"
 	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
@@ -32,6 +36,12 @@ import (
 const (
 	sourceSocket = "source.sock"
 	targetSocket = "target.sock"
+
+	msgSize          = 128
+	msgTypeAddRegion = 4
+	msgTypeAddRing   = 5
+	addRingFlagS2M   = 1
+	regionSize       = 4096
 )
 
 func TestClosingOpeningMemifProxy(t *testing.T) {
@@ -116,24 +126,89 @@ func TestStartProxyIfSocketFileIsExist(t *testing.T) {
 }
 
 func TestUpdateMetrics(t *testing.T) {
-	p1, err := proxy.New(sourceSocket, targetSocket, "unix", nil)
+	region, err := ioutil.TempFile("", "memif-region")
 	require.Nil(t, err)
-	p2, err := proxy.New(targetSocket, sourceSocket, "unix", nil)
+	defer func() {
+		_ = region.Close()
+		_ = os.Remove(region.Name())
+	}()
+	require.Nil(t, region.Truncate(regionSize))
+
+	targetListener, err := listenAndDiscard(targetSocket)
 	require.Nil(t, err)
-	err = p1.Start()
+	defer func() {
+		_ = targetListener.Close()
+	}()
+
+	p, err := proxy.New(sourceSocket, targetSocket, "unix", nil)
 	require.Nil(t, err)
-	err = p2.Start()
+	err = p.Start()
 	require.Nil(t, err)
-	err = connectAndSendMsg(sourceSocket)
+
+	addr, err := net.ResolveUnixAddr("unix", sourceSocket)
 	require.Nil(t, err)
-	require.Eventually(t, func() bool {
-		return len(p1.Metrics()) == 2 && p1.Metrics()["tx_bytes"] == "6"
-	}, time.Millisecond*200, time.Millisecond*50)
-	err = p1.Stop()
+	conn, err := net.DialUnix("unix", nil, addr)
+	require.Nil(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// Announce a region and a slave-to-master ring with 16 slots placed at its beginning
+	addRegion := make([]byte, msgSize)
+	binary.LittleEndian.PutUint16(addRegion, msgTypeAddRegion)
+	binary.LittleEndian.PutUint32(addRegion[4:], regionSize)
+	_, _, err = conn.WriteMsgUnix(addRegion, syscall.UnixRights(int(region.Fd())), nil)
+	require.Nil(t, err)
+	addRing := make([]byte, msgSize)
+	binary.LittleEndian.PutUint16(addRing, msgTypeAddRing)
+	binary.LittleEndian.PutUint16(addRing[2:], addRingFlagS2M)
+	addRing[12] = 4
+	_, _, err = conn.WriteMsgUnix(addRing, nil, nil)
+	require.Nil(t, err)
+
+	// Produce two descriptors of 60 and 40 bytes
+	desc := make([]byte, 4)
+	binary.LittleEndian.PutUint32(desc, 60)
+	_, err = region.WriteAt(desc, 128+4)
+	require.Nil(t, err)
+	binary.LittleEndian.PutUint32(desc, 40)
+	_, err = region.WriteAt(desc, 128+16+4)
 	require.Nil(t, err)
-	err = p2.Stop()
+	head := make([]byte, 2)
+	binary.LittleEndian.PutUint16(head, 2)
+	_, err = region.WriteAt(head, 6)
 	require.Nil(t, err)
-	t.Log(p1.Metrics())
+
+	require.Eventually(t, func() bool {
+		metrics := p.Metrics()
+		return metrics["tx_packets"] == "2" && metrics["tx_bytes"] == "100" && metrics["rx_packets"] == "0"
+	}, time.Second, time.Millisecond*50)
+	err = p.Stop()
+	require.Nil(t, err)
+	t.Log(p.Metrics())
+}
+
+func listenAndDiscard(sock string) (*net.UnixListener, error) {
+	_ = os.Remove(sock)
+	addr, err := net.ResolveUnixAddr("unix", sock)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.ListenUnix("unix", addr)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		conn, err := listener.AcceptUnix()
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		_, _ = io.Copy(ioutil.Discard, conn)
+	}()
+	return listener, nil
 }
 
 func connectAndSendMsg(sock string) error {
@@ -149,7 +149,7 @@ func TestServerShouldWriteMetrics(t *testing.T) {
 	require.Nil(t, err)
 	metrics := conn.Path.PathSegments[conn.Path.Index].Metrics
 	require.NotNil(t, metrics)
-	require.Equal(t, 2, len(metrics))
+	require.Equal(t, 4, len(metrics))
 	_, err = s.Close(clienturl.WithClientURL(ctx, &url.URL{}), r.Connection)
 	require.Nil(t, err)
 	checkThatProxyHasStopped(t, path.Join(dir, socketName))
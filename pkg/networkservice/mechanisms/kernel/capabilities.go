@@ -0,0 +1,151 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package kernel
+
+import (
+	"context"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	"google.golang.org/grpc"
+)
+
+const (
+	tunFilename = "/dev/net/tun"
+	// Linux AF_PACKET address family, spelled out so that the package still builds on non-Linux unix systems
+	afPacket = 17
+	// Names of the tap interface created by ProbeVPPTap, the host one must fit into kernel.LinuxIfMaxLength
+	probeTapName     = "kernel-tap-probe"
+	probeHostTapName = "nsm-tap-probe"
+)
+
+// Capabilities - describes which kernel interface implementations are usable on this host
+type Capabilities struct {
+	// Tap - vpp is able to create tapv2 interfaces: /dev/vhost-net and /dev/net/tun can be opened
+	Tap bool
+	// AfPacket - AF_PACKET sockets can be opened, so vpp can attach to the host side of a veth pair
+	AfPacket bool
+}
+
+// ProbeCapabilities - probes the host for the kernel interface implementations vpp is able to use
+func ProbeCapabilities() *Capabilities {
+	return &Capabilities{
+		Tap:      canOpen(vnetFilename) && canOpen(tunFilename),
+		AfPacket: canOpenSocket(afPacket),
+	}
+}
+
+// ProbeVPPTap - returns true if the vppagent behind vppagentCC succeeds in creating a tapv2 interface,
+//               the probe interface is deleted right away. It changes the dataplane, so it is up to the caller
+//               to run it, see WithVPPTapProbe for running it lazily on the first Request.
+func ProbeVPPTap(ctx context.Context, vppagentCC grpc.ClientConnInterface) bool {
+	if err := probeVPPTap(ctx, vppagentCC); err != nil {
+		logrus.Warnf("vpp is not able to create a tapv2 interface: %+v", err)
+		return false
+	}
+	return true
+}
+
+func probeVPPTap(ctx context.Context, vppagentCC grpc.ClientConnInterface) error {
+	client := configurator.NewConfiguratorServiceClient(vppagentCC)
+	conf := &configurator.Config{
+		VppConfig: &vpp.ConfigData{
+			Interfaces: []*vppinterfaces.Interface{
+				{
+					Name:    probeTapName,
+					Type:    vppinterfaces.Interface_TAP,
+					Enabled: true,
+					Link: &vppinterfaces.Interface_Tap{
+						Tap: &vppinterfaces.TapLink{
+							Version:    2,
+							HostIfName: probeHostTapName,
+						},
+					},
+				},
+			},
+		},
+	}
+	if _, err := client.Update(ctx, &configurator.UpdateRequest{Update: conf}); err != nil {
+		return err
+	}
+	if _, err := client.Delete(ctx, &configurator.DeleteRequest{Delete: conf}); err != nil {
+		logrus.Errorf("failed to delete the tapv2 probe interface %s: %+v", probeTapName, err)
+	}
+	return nil
+}
+
+// vppTapProbe - runs ProbeVPPTap once for all the elements sharing it, a probe cut short by the Request context is
+// retried on the next Request
+type vppTapProbe struct {
+	vppagentCC grpc.ClientConnInterface
+	done       bool
+	tap        bool
+	mu         sync.Mutex
+}
+
+func (p *vppTapProbe) supported(ctx context.Context) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.done {
+		err := probeVPPTap(ctx, p.vppagentCC)
+		if err != nil && ctx.Err() != nil {
+			return false
+		}
+		if err != nil {
+			logrus.Warnf("vpp is not able to create a tapv2 interface: %+v", err)
+		}
+		p.tap, p.done = err == nil, true
+	}
+	return p.tap
+}
+
+// Supports - returns true if the implementation impl is usable with these capabilities
+func (c *Capabilities) Supports(impl string) bool {
+	switch impl {
+	case TapImplementation:
+		return c.Tap
+	case VethPairImplementation:
+		return c.AfPacket
+	}
+	return false
+}
+
+func canOpen(filename string) bool {
+	file, err := os.OpenFile(filename, os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	_ = file.Close()
+	return true
+}
+
+func canOpenSocket(family int) bool {
+	fd, err := syscall.Socket(family, syscall.SOCK_RAW, 0)
+	if err != nil {
+		return false
+	}
+	_ = syscall.Close(fd)
+	return true
+}
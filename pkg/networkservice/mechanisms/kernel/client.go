@@ -19,11 +19,17 @@
 package kernel
 
 import (
-	"os"
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kernelvethpair"
 )
@@ -34,10 +40,51 @@ const (
 	vnetFilename = "/dev/vhost-net"
 )
 
+type kernelClient struct {
+	selector *selector
+	clients  map[string]networkservice.NetworkServiceClient
+}
+
 // NewClient return a NetworkServiceClient chain element that correctly handles the kernel Mechanism
-func NewClient() networkservice.NetworkServiceClient {
-	if _, err := os.Stat(vnetFilename); err == nil {
-		return kerneltap.NewClient()
+// The implementation is selected per connection: the one requested in the mechanism parameters, the one
+// set by WithDefaultImplementation or the first one supported by the host, tap being preferred over vethpair.
+// The selected implementation is recorded in the mechanism parameters under ImplementationKey.
+func NewClient(options ...Option) networkservice.NetworkServiceClient {
+	o := newOptions(options...)
+	return &kernelClient{
+		selector: newSelector(o),
+		clients: map[string]networkservice.NetworkServiceClient{
+			TapImplementation: next.NewNetworkServiceClient(
+				kerneltap.NewClient(kerneltap.WithNetNSResolver(o.netNSResolver), kerneltap.WithDefaultMTU(o.defaultMTU), kerneltap.WithUnderlayMTU(o.underlayMTU)),
+				newImplementationClient(TapImplementation),
+			),
+			VethPairImplementation: next.NewNetworkServiceClient(
+				kernelvethpair.NewClient(kernelvethpair.WithNetNSResolver(o.netNSResolver), kernelvethpair.WithDefaultMTU(o.defaultMTU), kernelvethpair.WithUnderlayMTU(o.underlayMTU)),
+				newImplementationClient(VethPairImplementation),
+			),
+		},
+	}
+}
+
+func (k *kernelClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	// On refresh the connection may already use another mechanism, in which case the default implementation is
+	// offered for the kernel mechanism preference
+	impl, err := k.selector.selectImplementation(ctx, kernelMechanism(request.GetConnection().GetMechanism()))
+	if err != nil {
+		return nil, err
+	}
+	return k.clients[impl].Request(ctx, request, opts...)
+}
+
+func (k *kernelClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	mechanism := kernelMechanism(conn.GetMechanism())
+	if mechanism == nil {
+		return next.Client(ctx).Close(ctx, conn, opts...)
+	}
+	impl, err := k.selector.selectImplementation(ctx, mechanism)
+	if err != nil {
+		logrus.Errorf("can't select the kernel mechanism implementation to close connection %s: %+v", conn.GetId(), err)
+		return next.Client(ctx).Close(ctx, conn, opts...)
 	}
-	return kernelvethpair.NewClient()
+	return k.clients[impl].Close(ctx, conn, opts...)
 }
@@ -0,0 +1,50 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package kernel_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	kernelmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func TestKernelClientRecordsImplementation(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	client := kernel.NewClient(kernel.WithCapabilities(&kernel.Capabilities{Tap: true, AfPacket: true}))
+
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+		},
+	}
+	_, err := client.Request(vppagent.WithConfig(context.Background()), request)
+	require.NoError(t, err)
+	require.Len(t, request.GetMechanismPreferences(), 1)
+	require.Equal(t, kernelmech.MECHANISM, request.GetMechanismPreferences()[0].GetType())
+	require.Equal(t, kernel.TapImplementation, request.GetMechanismPreferences()[0].GetParameters()[kernel.ImplementationKey])
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package kernel
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+const (
+	// ImplementationKey - mechanism parameter containing the implementation used for the kernel interface
+	ImplementationKey = "implementation"
+	// TapImplementation - the kernel interface is a tapv2 interface
+	TapImplementation = "tap"
+	// VethPairImplementation - the kernel interface is one side of a veth pair, vpp attaches to the other one
+	// using AF_PACKET
+	VethPairImplementation = "vethpair"
+	// afXDPImplementation - vpp-agent v3.1.0 can't configure AF_XDP interfaces, so vpp can't attach to the veth pair
+	// with AF_XDP and the requests for it are rejected explicitly
+	afXDPImplementation = "afxdp"
+)
+
+// preferredImplementations - implementations in the order they are selected when nothing is requested
+var preferredImplementations = []string{TapImplementation, VethPairImplementation}
+
+type selector struct {
+	caps        *Capabilities
+	tapProbe    *vppTapProbe
+	defaultImpl string
+}
+
+func newSelector(o *options) *selector {
+	return &selector{
+		caps:        o.caps,
+		tapProbe:    o.tapProbe,
+		defaultImpl: o.defaultImpl,
+	}
+}
+
+// capabilities - returns the capabilities of the host narrowed by the vpp tap probe
+func (s *selector) capabilities(ctx context.Context) *Capabilities {
+	if s.tapProbe == nil || !s.caps.Tap {
+		return s.caps
+	}
+	return &Capabilities{
+		Tap:      s.tapProbe.supported(ctx),
+		AfPacket: s.caps.AfPacket,
+	}
+}
+
+// selectImplementation - returns the implementation requested in the mechanism parameters, the default one or
+// the first supported one
+func (s *selector) selectImplementation(ctx context.Context, mechanism *networkservice.Mechanism) (string, error) {
+	caps := s.capabilities(ctx)
+	impl := mechanism.GetParameters()[ImplementationKey]
+	if impl == "" {
+		impl = s.defaultImpl
+	}
+	if impl == afXDPImplementation {
+		return "", errors.Errorf("kernel mechanism implementation %q is not supported by vpp-agent v3.1.0", impl)
+	}
+	if impl != "" {
+		if !caps.Supports(impl) {
+			return "", errors.Errorf("kernel mechanism implementation %q is not supported on this host: %+v", impl, caps)
+		}
+		return impl, nil
+	}
+	for _, impl := range preferredImplementations {
+		if caps.Supports(impl) {
+			return impl, nil
+		}
+	}
+	// vpp may be able to do more than we were able to probe, so fall back to the most basic implementation
+	logrus.Warnf("no kernel mechanism implementation is detected on this host: %+v, falling back to %q", caps, VethPairImplementation)
+	return VethPairImplementation, nil
+}
+
+// kernelMechanism - returns mechanism if it is a kernel one and nil otherwise, so the parameters of other
+// mechanisms are never taken for an implementation request
+func kernelMechanism(mechanism *networkservice.Mechanism) *networkservice.Mechanism {
+	if kernel.ToMechanism(mechanism) == nil {
+		return nil
+	}
+	return mechanism
+}
+
+func setImplementation(mechanism *networkservice.Mechanism, impl string) {
+	if mechanism == nil {
+		return
+	}
+	if mechanism.GetParameters() == nil {
+		mechanism.Parameters = map[string]string{}
+	}
+	mechanism.GetParameters()[ImplementationKey] = impl
+}
+
+// implementationClient - records impl in the kernel mechanisms the client prefers and in the selected one
+type implementationClient struct {
+	impl string
+}
+
+func newImplementationClient(impl string) networkservice.NetworkServiceClient {
+	return &implementationClient{impl: impl}
+}
+
+func (i *implementationClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	for _, mechanism := range request.GetMechanismPreferences() {
+		if kernel.ToMechanism(mechanism) != nil {
+			setImplementation(mechanism, i.impl)
+		}
+	}
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if kernel.ToMechanism(conn.GetMechanism()) != nil {
+		setImplementation(conn.GetMechanism(), i.impl)
+	}
+	return conn, nil
+}
+
+func (i *implementationClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
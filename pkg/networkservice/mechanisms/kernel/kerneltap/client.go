@@ -31,13 +31,23 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifnames"
 )
 
-type kernelTapClient struct{}
+type kernelTapClient struct {
+	names   *ifnames.Registry
+	options *options
+}
 
 // NewClient provides NetworkServiceClient chain elements that support the kernel Mechanism using tapv2
-func NewClient() networkservice.NetworkServiceClient {
-	return &kernelTapClient{}
+func NewClient(options ...Option) networkservice.NetworkServiceClient {
+	o := newOptions(options...)
+	return &kernelTapClient{
+		names:   ifnames.ProcessRegistry(),
+		options: o,
+	}
 }
 
 func (k *kernelTapClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
@@ -50,7 +60,7 @@ func (k *kernelTapClient) Request(ctx context.Context, request *networkservice.N
 	if err != nil {
 		return nil, err
 	}
-	if err := appendInterfaceConfig(ctx, conn, fmt.Sprintf("client-%s", conn.GetId())); err != nil {
+	if err := appendInterfaceConfig(ctx, conn, fmt.Sprintf("client-%s", conn.GetId()), k.options, k.names); err != nil {
 		return nil, err
 	}
 	return conn, nil
@@ -61,9 +71,15 @@ func (k *kernelTapClient) Close(ctx context.Context, conn *networkservice.Connec
 	if err != nil {
 		return nil, err
 	}
-	err = appendInterfaceConfig(ctx, conn, fmt.Sprintf("client-%s", conn.GetId()))
+	err = appendInterfaceConfig(ctx, conn, fmt.Sprintf("client-%s", conn.GetId()), k.options, nil)
 	if err != nil {
 		return nil, err
 	}
+	// The interfaces are deleted by the commit, which comes after the client chain: the names and the pinned netns
+	// have to outlive it
+	vppagent.AfterCommit(ctx, func() {
+		k.names.Release(conn.GetId())
+		k.options.netNSResolver.Release(fmt.Sprintf("client-%s", conn.GetId()))
+	})
 	return rv, err
 }
@@ -17,12 +17,15 @@
 package kerneltap_test
 
 import (
+	"context"
 	"io/ioutil"
 	"net/url"
 	"testing"
 
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
@@ -30,6 +33,7 @@ import (
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 )
 
 func TestKernelTapClient(t *testing.T) {
@@ -69,3 +73,40 @@ func TestKernelTapClient(t *testing.T) {
 		testConnToClose,
 	))
 }
+
+func TestKernelTapClientReleasesAfterCommit(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := func(id string) *networkservice.NetworkServiceRequest {
+		return &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{
+				Id: id,
+				Mechanism: &networkservice.Mechanism{
+					Cls:  cls.LOCAL,
+					Type: kernel.MECHANISM,
+					Parameters: map[string]string{
+						kernel.NetNSURL:         (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+						kernel.InterfaceNameKey: "nsm-release",
+					},
+				},
+			},
+		}
+	}
+	client := kerneltap.NewClient()
+	conn, err := client.Request(vppagent.WithConfig(context.Background()), request("conn-1"))
+	require.NoError(t, err)
+
+	ctx := vppagent.WithConfig(context.Background())
+	_, err = client.Close(ctx, conn)
+	require.NoError(t, err)
+	// The interface isn't deleted until the commit, so its name is still taken
+	_, err = client.Request(vppagent.WithConfig(context.Background()), request("conn-2"))
+	require.Error(t, err)
+
+	require.NoError(t, vppagent.Commit(ctx, true, func(_, _ *configurator.Config) error {
+		return nil
+	}))
+	conn, err = client.Request(vppagent.WithConfig(context.Background()), request("conn-2"))
+	require.NoError(t, err)
+	_, err = client.Close(vppagent.WithConfig(context.Background()), conn)
+	require.NoError(t, err)
+}
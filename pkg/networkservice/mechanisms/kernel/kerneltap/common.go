@@ -19,10 +19,9 @@ package kerneltap
 
 import (
 	"context"
-	"net/url"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
-	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
@@ -33,28 +32,110 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifnames"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifparams"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
 )
 
-const (
-	fileScheme = "file"
-)
+// Option - option for NewClient and NewServer
+type Option func(o *options)
+
+type options struct {
+	netNSResolver *netnsurl.Resolver
+	defaultMTU    uint32
+	underlayMTU   ifparams.UnderlayMTUFunc
+}
+
+// WithNetNSResolver - sets the resolver of the kernel mechanism NetNSURL, by default all the netnsurl schemes except
+//                     netnsurl.ContainerScheme are supported
+func WithNetNSResolver(resolver *netnsurl.Resolver) Option {
+	return func(o *options) {
+		o.netNSResolver = resolver
+	}
+}
+
+// WithDefaultMTU - sets the MTU of the interfaces which mechanism doesn't have the ifparams.MTUKey parameter
+func WithDefaultMTU(mtu uint32) Option {
+	return func(o *options) {
+		o.defaultMTU = mtu
+	}
+}
+
+// WithUnderlayMTU - sets the MTU of the interfaces cross connected to a vxlan or srv6 tunnel to the MTU returned by
+//                   underlayMTU less the tunnel overhead, unless the mechanism has the ifparams.MTUKey parameter or
+//                   WithDefaultMTU is set
+func WithUnderlayMTU(underlayMTU ifparams.UnderlayMTUFunc) Option {
+	return func(o *options) {
+		o.underlayMTU = underlayMTU
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		netNSResolver: netnsurl.NewResolver(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
 
-func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, name string) error {
+// appendInterfaceConfig - appends the config for the tap interface of the conn, the host interface name is reserved in
+//                         names on Request, names is nil on Close
+func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, name string, o *options, names *ifnames.Registry) error {
 	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		netNSURLStr := mechanism.GetNetNSURL()
-		netNSURL, err := url.Parse(netNSURLStr)
+		netNS, err := o.netNSResolver.ResolveFor(name, mechanism.GetNetNSURL())
 		if err != nil {
+			if names != nil {
+				return err
+			}
+			// The network namespace may be already gone on Close, vpp-agent doesn't need it to delete the interfaces
+			logrus.Warnf("can't resolve the network namespace of the connection %s: %v", conn.GetId(), err)
+			netNS = &netnsurl.NetNS{Namespace: &linuxnamespace.NetNamespace{}}
+		}
+		params, err := ifparams.FromMechanism(mechanism)
+		if err != nil {
+			return err
+		}
+		if err = ifparams.Reject(mechanism, "tap", ifparams.ChecksumOffloadKey); err != nil {
 			return err
 		}
-		if netNSURL.Scheme != fileScheme {
-			return errors.Errorf("kernel.ToMechanism(conn.GetMechanism()).GetNetNSURL() must be of scheme %q: %q", fileScheme, netNSURL)
+		if params.MTU == 0 {
+			params.MTU = o.defaultMTU
 		}
-		vppagentConfigTemplate(vppagent.Config(ctx), name, kernel.ToMechanism(conn.GetMechanism()).GetInterfaceName(conn), netNSURL.Path)
+		ifaceName := ifnames.ForConnection(mechanism, conn)
+		if names != nil {
+			if err := names.Reserve(netNS.Filename, ifaceName, conn.GetId()); err != nil {
+				return err
+			}
+		}
+		if names != nil && params.MTU == 0 {
+			setTunnelMTU(ctx, o, name)
+		}
+		vppagentConfigTemplate(vppagent.Config(ctx), name, ifaceName, netNS.Namespace, params)
 	}
 	return nil
 }
 
-func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFilename string) {
+// setTunnelMTU - sets the MTU of the interfaces named names when the config committed for the connection turns out to
+//                cross connect them to a tunnel, the other side of the cross connect isn't known before the commit
+func setTunnelMTU(ctx context.Context, o *options, names ...string) {
+	if o.underlayMTU == nil {
+		return
+	}
+	underlayMTU, err := o.underlayMTU(ctx)
+	if err != nil {
+		logrus.Warnf("can't get the underlay MTU, the MTU of %v is left to the default: %+v", names, err)
+		return
+	}
+	vppagent.OnCommit(ctx, func(configs *vppagent.CommitConfigs) {
+		if err := ifparams.SetTunnelMTU(configs.Update(), underlayMTU, names...); err != nil {
+			logrus.Warnf("the MTU of %v is left to the default: %+v", names, err)
+		}
+	})
+}
+
+func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName string, netNS *linuxnamespace.NetNamespace, params *ifparams.Params) {
 	// We append an Interfaces.  Interfaces creates the vpp side of an interface.
 	//   In this case, a Tapv2 interface that has one side in vpp, and the other
 	//   as a Linux kernel interface
@@ -62,24 +143,26 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 		Name:    name,
 		Type:    vppinterfaces.Interface_TAP,
 		Enabled: true,
+		Mtu:     params.MTU,
 		Link: &vppinterfaces.Interface_Tap{
 			Tap: &vppinterfaces.TapLink{
-				Version: 2,
+				Version:    2,
+				RxRingSize: params.RxRingSize,
+				TxRingSize: params.TxRingSize,
+				EnableGso:  params.GSO,
 			},
 		},
 	})
 	// We apply configuration to LinuxInterfaces
 	// Important details:
-	//    - LinuxInterfaces.HostIfName - must be no longer than 15 chars (linux limitation)
+	//    - LinuxInterfaces.HostIfName - must be no longer than 15 chars (linux limitation), ifnames takes care of it
 	conf.GetLinuxConfig().Interfaces = append(conf.GetLinuxConfig().Interfaces, &linux.Interface{
 		Name:       name,
 		Type:       linuxinterfaces.Interface_TAP_TO_VPP,
 		Enabled:    true,
-		HostIfName: linuxIfaceName(ifaceName),
-		Namespace: &linuxnamespace.NetNamespace{
-			Type:      linuxnamespace.NetNamespace_FD,
-			Reference: netnsFilename,
-		},
+		HostIfName: ifaceName,
+		Mtu:        params.MTU,
+		Namespace:  netNS,
 		Link: &linuxinterfaces.Interface_Tap{
 			Tap: &linuxinterfaces.TapLink{
 				VppTapIfName: name,
@@ -87,10 +170,3 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 		},
 	})
 }
-
-func linuxIfaceName(ifaceName string) string {
-	if len(ifaceName) <= kernel.LinuxIfMaxLength {
-		return ifaceName
-	}
-	return ifaceName[:kernel.LinuxIfMaxLength]
-}
@@ -30,36 +30,52 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifnames"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
-type kernelTapServer struct{}
+type kernelTapServer struct {
+	names   *ifnames.Registry
+	options *options
+}
 
 // NewServer provides NetworkServiceServer chain elements that support the kernel Mechanism using tapv2
-func NewServer() networkservice.NetworkServiceServer {
-	return &kernelTapServer{}
+func NewServer(options ...Option) networkservice.NetworkServiceServer {
+	o := newOptions(options...)
+	return &kernelTapServer{
+		names:   ifnames.ProcessRegistry(),
+		options: o,
+	}
 }
 
 func (k *kernelTapServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
 	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil {
-		err := appendInterfaceConfig(ctx, request.GetConnection(), fmt.Sprintf("server-%s", request.GetConnection().GetId()))
+		err := appendInterfaceConfig(ctx, request.GetConnection(), fmt.Sprintf("server-%s", request.GetConnection().GetId()), k.options, k.names)
 		if err != nil {
 			return nil, err
 		}
 		linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
 		ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
 	}
-	return next.Server(ctx).Request(ctx, request)
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		k.names.Release(request.GetConnection().GetId())
+		k.options.netNSResolver.Release(fmt.Sprintf("server-%s", request.GetConnection().GetId()))
+		return nil, err
+	}
+	return conn, nil
 }
 
 func (k *kernelTapServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
 	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		err := appendInterfaceConfig(ctx, conn, fmt.Sprintf("server-%s", conn.GetId()))
+		err := appendInterfaceConfig(ctx, conn, fmt.Sprintf("server-%s", conn.GetId()), k.options, nil)
 		if err != nil {
 			return nil, err
 		}
 		linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
 		ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
+		defer k.names.Release(conn.GetId())
+		defer k.options.netNSResolver.Release(fmt.Sprintf("server-%s", conn.GetId()))
 	}
 	return next.Server(ctx).Close(ctx, conn)
 }
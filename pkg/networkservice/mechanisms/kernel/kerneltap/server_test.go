@@ -17,12 +17,18 @@
 package kerneltap_test
 
 import (
+	"context"
 	"io/ioutil"
 	"net/url"
 	"testing"
 
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
@@ -30,6 +36,8 @@ import (
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifparams"
 )
 
 func TestKernelTapServer(t *testing.T) {
@@ -63,3 +71,164 @@ func TestKernelTapServer(t *testing.T) {
 		testConnToClose,
 	))
 }
+
+func TestKernelTapServerInterfaceParams(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+				Parameters: map[string]string{
+					kernel.NetNSURL:        (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+					ifparams.MTUKey:        "1450",
+					ifparams.RxRingSizeKey: "1024",
+					ifparams.TxRingSizeKey: "2048",
+					ifparams.GSOKey:        "true",
+				},
+			},
+		},
+	}
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := kerneltap.NewServer().Request(ctx, request)
+	require.NoError(t, err)
+
+	vppInterfaces := vppagent.Config(ctx).GetVppConfig().GetInterfaces()
+	require.Len(t, vppInterfaces, 1)
+	assert.Equal(t, uint32(1450), vppInterfaces[0].GetMtu())
+	assert.Equal(t, uint32(1024), vppInterfaces[0].GetTap().GetRxRingSize())
+	assert.Equal(t, uint32(2048), vppInterfaces[0].GetTap().GetTxRingSize())
+	assert.True(t, vppInterfaces[0].GetTap().GetEnableGso())
+	linuxInterfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+	require.Len(t, linuxInterfaces, 1)
+	assert.Equal(t, uint32(1450), linuxInterfaces[0].GetMtu())
+
+	request.GetConnection().GetMechanism().GetParameters()[ifparams.MTUKey] = "jumbo"
+	_, err = kerneltap.NewServer().Request(vppagent.WithConfig(context.Background()), request)
+	require.Error(t, err)
+}
+
+func TestKernelTapServerRejectsUnsupportedParams(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	for key, value := range map[string]string{
+		ifparams.RxRingSizeKey:      "1000",
+		ifparams.TxRingSizeKey:      "65536",
+		ifparams.QueuesKey:          "4",
+		ifparams.ChecksumOffloadKey: "true",
+	} {
+		request := &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{
+				Id: "ConnectionId",
+				Mechanism: &networkservice.Mechanism{
+					Cls:  cls.LOCAL,
+					Type: kernel.MECHANISM,
+					Parameters: map[string]string{
+						kernel.NetNSURL: (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+						key:             value,
+					},
+				},
+			},
+		}
+		_, err := kerneltap.NewServer().Request(vppagent.WithConfig(context.Background()), request)
+		require.Error(t, err, key)
+	}
+}
+
+func TestKernelTapServerDefaultMTU(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+				Parameters: map[string]string{
+					kernel.NetNSURL: (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+				},
+			},
+		},
+	}
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := kerneltap.NewServer(kerneltap.WithDefaultMTU(1450)).Request(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1450), vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetMtu())
+	assert.Equal(t, uint32(1450), vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetMtu())
+}
+
+func TestKernelTapServerTunnelMTU(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+				Parameters: map[string]string{
+					kernel.NetNSURL: (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+				},
+			},
+		},
+	}
+	server := kerneltap.NewServer(kerneltap.WithUnderlayMTU(ifparams.StaticUnderlayMTU(1500)))
+	commit := func(tunnel bool) *configurator.Config {
+		ctx := vppagent.WithConfig(context.Background())
+		_, err := server.Request(ctx, request)
+		require.NoError(t, err)
+		if tunnel {
+			vppagent.Config(ctx).GetVppConfig().Interfaces = append(vppagent.Config(ctx).GetVppConfig().GetInterfaces(), &vppinterfaces.Interface{
+				Name: "client-ConnectionId",
+				Type: vppinterfaces.Interface_VXLAN_TUNNEL,
+				Link: &vppinterfaces.Interface_Vxlan{
+					Vxlan: &vppinterfaces.VxlanLink{SrcAddress: "10.0.0.1", DstAddress: "10.0.0.2"},
+				},
+			})
+		}
+		var update *configurator.Config
+		require.NoError(t, vppagent.Commit(ctx, false, func(u, _ *configurator.Config) error {
+			update = u
+			return nil
+		}))
+		return update
+	}
+	update := commit(true)
+	assert.Equal(t, uint32(1450), update.GetVppConfig().GetInterfaces()[0].GetMtu())
+	assert.Equal(t, uint32(1450), update.GetLinuxConfig().GetInterfaces()[0].GetMtu())
+
+	// A local cross connect keeps the default MTU
+	update = commit(false)
+	assert.Equal(t, uint32(0), update.GetVppConfig().GetInterfaces()[0].GetMtu())
+	assert.Equal(t, uint32(0), update.GetLinuxConfig().GetInterfaces()[0].GetMtu())
+}
+
+func TestKernelTapServerNetNSReferences(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+				Parameters: map[string]string{
+					kernel.NetNSURL: "pid://12",
+				},
+			},
+		},
+	}
+	server := kerneltap.NewServer()
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, request)
+	require.NoError(t, err)
+	linuxInterfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+	require.Len(t, linuxInterfaces, 1)
+	assert.Equal(t, linuxnamespace.NetNamespace_PID, linuxInterfaces[0].GetNamespace().GetType())
+	assert.Equal(t, "12", linuxInterfaces[0].GetNamespace().GetReference())
+
+	// Container references need a resolver
+	request.GetConnection().GetMechanism().GetParameters()[kernel.NetNSURL] = "container://c0ffee"
+	_, err = server.Request(vppagent.WithConfig(context.Background()), request)
+	require.Error(t, err)
+	// but the interfaces are still deleted on Close
+	_, err = server.Close(vppagent.WithConfig(context.Background()), request.GetConnection())
+	require.NoError(t, err)
+}
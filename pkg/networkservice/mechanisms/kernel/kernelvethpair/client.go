@@ -20,6 +20,7 @@ package kernelvethpair
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"google.golang.org/grpc"
@@ -30,13 +31,23 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifnames"
 )
 
-type kernelVethPairClient struct{}
+type kernelVethPairClient struct {
+	names   *ifnames.Registry
+	options *options
+}
 
 // NewClient provides NetworkServiceClient chain elements that support the kernel Mechanism using veth pairs
-func NewClient() networkservice.NetworkServiceClient {
-	return &kernelVethPairClient{}
+func NewClient(options ...Option) networkservice.NetworkServiceClient {
+	o := newOptions(options...)
+	return &kernelVethPairClient{
+		names:   ifnames.ProcessRegistry(),
+		options: o,
+	}
 }
 
 func (k *kernelVethPairClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
@@ -49,7 +60,7 @@ func (k *kernelVethPairClient) Request(ctx context.Context, request *networkserv
 	if err != nil {
 		return nil, err
 	}
-	if err := appendInterfaceConfig(ctx, conn, "client"); err != nil {
+	if err := appendInterfaceConfig(ctx, conn, "client", k.options, k.names); err != nil {
 		return nil, err
 	}
 	return conn, nil
@@ -60,9 +71,15 @@ func (k *kernelVethPairClient) Close(ctx context.Context, conn *networkservice.C
 	if err != nil {
 		return nil, err
 	}
-	err = appendInterfaceConfig(ctx, conn, "client")
+	err = appendInterfaceConfig(ctx, conn, "client", k.options, nil)
 	if err != nil {
 		return nil, err
 	}
+	// The interfaces are deleted by the commit, which comes after the client chain: the names and the pinned netns
+	// have to outlive it
+	vppagent.AfterCommit(ctx, func() {
+		k.names.Release(conn.GetId())
+		k.options.netNSResolver.Release(fmt.Sprintf("client-%s", conn.GetId()))
+	})
 	return rv, err
 }
@@ -19,50 +19,143 @@ package kernelvethpair
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
-	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
 	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
 	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifnames"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifparams"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
 )
 
-const (
-	fileScheme = "file"
-)
+// Option - option for NewClient and NewServer
+type Option func(o *options)
+
+type options struct {
+	netNSResolver *netnsurl.Resolver
+	defaultMTU    uint32
+	underlayMTU   ifparams.UnderlayMTUFunc
+}
+
+// WithNetNSResolver - sets the resolver of the kernel mechanism NetNSURL, by default all the netnsurl schemes except
+//                     netnsurl.ContainerScheme are supported
+func WithNetNSResolver(resolver *netnsurl.Resolver) Option {
+	return func(o *options) {
+		o.netNSResolver = resolver
+	}
+}
+
+// WithDefaultMTU - sets the MTU of the interfaces which mechanism doesn't have the ifparams.MTUKey parameter
+func WithDefaultMTU(mtu uint32) Option {
+	return func(o *options) {
+		o.defaultMTU = mtu
+	}
+}
 
-func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, prefix string) error {
+// WithUnderlayMTU - sets the MTU of the interfaces cross connected to a vxlan or srv6 tunnel to the MTU returned by
+//                   underlayMTU less the tunnel overhead, unless the mechanism has the ifparams.MTUKey parameter or
+//                   WithDefaultMTU is set
+func WithUnderlayMTU(underlayMTU ifparams.UnderlayMTUFunc) Option {
+	return func(o *options) {
+		o.underlayMTU = underlayMTU
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		netNSResolver: netnsurl.NewResolver(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// appendInterfaceConfig - appends the config for the veth pair of the conn, the host interface names of both veth ends
+//                         are reserved in names on Request, names is nil on Close
+func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, prefix string, o *options, names *ifnames.Registry) error {
 	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		netNSURLStr := mechanism.GetNetNSURL()
-		netNSURL, err := url.Parse(netNSURLStr)
+		netNS, err := o.netNSResolver.ResolveFor(fmt.Sprintf("%s-%s", prefix, conn.GetId()), mechanism.GetNetNSURL())
 		if err != nil {
+			if names != nil {
+				return err
+			}
+			// The network namespace may be already gone on Close, vpp-agent doesn't need it to delete the interfaces
+			logrus.Warnf("can't resolve the network namespace of the connection %s: %v", conn.GetId(), err)
+			netNS = &netnsurl.NetNS{Namespace: &linuxnamespace.NetNamespace{}}
+		}
+		params, err := ifparams.FromMechanism(mechanism)
+		if err != nil {
+			return err
+		}
+		if err = ifparams.Reject(mechanism, "vethpair", ifparams.RxRingSizeKey, ifparams.TxRingSizeKey, ifparams.GSOKey); err != nil {
 			return err
 		}
-		if netNSURL.Scheme != fileScheme {
-			return errors.Errorf("kernel.ToMechanism(conn.GetMechanism()).GetNetNSURL() must be of scheme %q: %q", fileScheme, netNSURL)
+		if params.MTU == 0 {
+			params.MTU = o.defaultMTU
+		}
+		// The vpp side of the veth pair stays in the current netns
+		vethIfaceName := ifnames.Generate(prefix, conn.GetId())
+		ifaceName := ifnames.ForConnection(mechanism, conn)
+		if names != nil {
+			if err := names.Reserve("", vethIfaceName, conn.GetId()); err != nil {
+				return err
+			}
+			if err := names.Reserve(netNS.Filename, ifaceName, conn.GetId()); err != nil {
+				names.Release(conn.GetId())
+				return err
+			}
 		}
-		vppagentConfigTemplate(vppagent.Config(ctx), fmt.Sprintf("%s-%s", prefix, conn.GetId()), kernel.ToMechanism(conn.GetMechanism()).GetInterfaceName(conn), netNSURL.Path)
+		name := fmt.Sprintf("%s-%s", prefix, conn.GetId())
+		if names != nil && params.MTU == 0 {
+			setTunnelMTU(ctx, o, name+"-veth", name)
+		}
+		vppagentConfigTemplate(vppagent.Config(ctx), name, vethIfaceName, ifaceName, netNS.Namespace, params)
 	}
 	return nil
 }
 
-func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFilename string) {
+// setTunnelMTU - sets the MTU of the interfaces named names when the config committed for the connection turns out to
+//                cross connect them to a tunnel, the other side of the cross connect isn't known before the commit
+func setTunnelMTU(ctx context.Context, o *options, names ...string) {
+	if o.underlayMTU == nil {
+		return
+	}
+	underlayMTU, err := o.underlayMTU(ctx)
+	if err != nil {
+		logrus.Warnf("can't get the underlay MTU, the MTU of %v is left to the default: %+v", names, err)
+		return
+	}
+	vppagent.OnCommit(ctx, func(configs *vppagent.CommitConfigs) {
+		if err := ifparams.SetTunnelMTU(configs.Update(), underlayMTU, names...); err != nil {
+			logrus.Warnf("the MTU of %v is left to the default: %+v", names, err)
+		}
+	})
+}
+
+func vppagentConfigTemplate(conf *configurator.Config, name, vethIfaceName, ifaceName string, netNS *linuxnamespace.NetNamespace, params *ifparams.Params) {
+	checksumOffloading := linuxinterfaces.VethLink_CHKSM_OFFLOAD_DISABLED
+	if params.ChecksumOffload {
+		checksumOffloading = linuxinterfaces.VethLink_CHKSM_OFFLOAD_ENABLED
+	}
 	conf.GetLinuxConfig().Interfaces = append(conf.GetLinuxConfig().Interfaces,
 		&linuxinterfaces.Interface{
 			Name:       name + "-veth",
 			Type:       linuxinterfaces.Interface_VETH,
 			Enabled:    true,
-			HostIfName: linuxIfaceName(name),
+			HostIfName: vethIfaceName,
+			Mtu:        params.MTU,
 			Link: &linuxinterfaces.Interface_Veth{
 				Veth: &linuxinterfaces.VethLink{
 					PeerIfName:           name,
-					RxChecksumOffloading: linuxinterfaces.VethLink_CHKSM_OFFLOAD_DISABLED,
-					TxChecksumOffloading: linuxinterfaces.VethLink_CHKSM_OFFLOAD_DISABLED,
+					RxChecksumOffloading: checksumOffloading,
+					TxChecksumOffloading: checksumOffloading,
 				},
 			},
 		},
@@ -70,16 +163,14 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 			Name:       name,
 			Type:       linuxinterfaces.Interface_VETH,
 			Enabled:    true,
-			HostIfName: linuxIfaceName(ifaceName),
-			Namespace: &linuxnamespace.NetNamespace{
-				Type:      linuxnamespace.NetNamespace_FD,
-				Reference: netnsFilename,
-			},
+			HostIfName: ifaceName,
+			Mtu:        params.MTU,
+			Namespace:  netNS,
 			Link: &linuxinterfaces.Interface_Veth{
 				Veth: &linuxinterfaces.VethLink{
 					PeerIfName:           name + "-veth",
-					RxChecksumOffloading: linuxinterfaces.VethLink_CHKSM_OFFLOAD_DISABLED,
-					TxChecksumOffloading: linuxinterfaces.VethLink_CHKSM_OFFLOAD_DISABLED,
+					RxChecksumOffloading: checksumOffloading,
+					TxChecksumOffloading: checksumOffloading,
 				},
 			},
 		})
@@ -87,17 +178,11 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 		Name:    name,
 		Type:    vppinterfaces.Interface_AF_PACKET,
 		Enabled: true,
+		Mtu:     params.MTU,
 		Link: &vppinterfaces.Interface_Afpacket{
 			Afpacket: &vppinterfaces.AfpacketLink{
-				HostIfName: linuxIfaceName(name),
+				HostIfName: vethIfaceName,
 			},
 		},
 	})
 }
-
-func linuxIfaceName(ifaceName string) string {
-	if len(ifaceName) <= kernel.LinuxIfMaxLength {
-		return ifaceName
-	}
-	return ifaceName[:kernel.LinuxIfMaxLength]
-}
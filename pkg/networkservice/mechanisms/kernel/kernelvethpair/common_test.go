@@ -29,6 +29,8 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifnames"
 )
 
 const (
@@ -63,6 +65,9 @@ func checkVppAgentConfig(prefix string, request *networkservice.NetworkServiceRe
 		assert.Equal(t, linuxnamespace.NetNamespace_FD, linuxInterfaces[1].GetNamespace().GetType())
 		assert.Equal(t, netnsFileURL, linuxInterfaces[1].GetNamespace().GetReference())
 
+		// Check vpp side interface name
+		assert.Equal(t, ifnames.Generate(prefix, request.GetConnection().GetId()), linuxInterfaces[0].GetHostIfName())
+
 		// Check vethpair peers are correct
 		assert.Equal(t, linuxInterfaces[0].GetName(), veths[1].GetPeerIfName())
 		assert.Equal(t, linuxInterfaces[1].GetName(), veths[0].GetPeerIfName())
@@ -17,6 +17,7 @@
 package kernelvethpair_test
 
 import (
+	"context"
 	"io/ioutil"
 	"net/url"
 	"testing"
@@ -25,10 +26,15 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kernelvethpair"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifparams"
 )
 
 func TestKernelVethPairServer(t *testing.T) {
@@ -62,3 +68,60 @@ func TestKernelVethPairServer(t *testing.T) {
 		testConnToClose,
 	))
 }
+
+func TestKernelVethPairServerInterfaceParams(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+				Parameters: map[string]string{
+					kernel.NetNSURL:             (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+					ifparams.MTUKey:             "1450",
+					ifparams.ChecksumOffloadKey: "true",
+				},
+			},
+		},
+	}
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := kernelvethpair.NewServer().Request(ctx, request)
+	require.NoError(t, err)
+
+	vppInterfaces := vppagent.Config(ctx).GetVppConfig().GetInterfaces()
+	require.Len(t, vppInterfaces, 1)
+	assert.Equal(t, uint32(1450), vppInterfaces[0].GetMtu())
+	linuxInterfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+	require.Len(t, linuxInterfaces, 2)
+	for _, linuxInterface := range linuxInterfaces {
+		assert.Equal(t, uint32(1450), linuxInterface.GetMtu())
+		assert.Equal(t, linuxinterfaces.VethLink_CHKSM_OFFLOAD_ENABLED, linuxInterface.GetVeth().GetRxChecksumOffloading())
+		assert.Equal(t, linuxinterfaces.VethLink_CHKSM_OFFLOAD_ENABLED, linuxInterface.GetVeth().GetTxChecksumOffloading())
+	}
+
+	request.GetConnection().GetMechanism().GetParameters()[ifparams.ChecksumOffloadKey] = "maybe"
+	_, err = kernelvethpair.NewServer().Request(vppagent.WithConfig(context.Background()), request)
+	require.Error(t, err)
+}
+
+func TestKernelVethPairServerRejectsTapParams(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	for _, key := range []string{ifparams.RxRingSizeKey, ifparams.TxRingSizeKey, ifparams.GSOKey} {
+		request := &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{
+				Id: "ConnectionId",
+				Mechanism: &networkservice.Mechanism{
+					Cls:  cls.LOCAL,
+					Type: kernel.MECHANISM,
+					Parameters: map[string]string{
+						kernel.NetNSURL: (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+						key:             "1024",
+					},
+				},
+			},
+		}
+		_, err := kernelvethpair.NewServer().Request(vppagent.WithConfig(context.Background()), request)
+		require.Error(t, err, key)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package kernel
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifparams"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
+)
+
+// Option - option for NewClient and NewServer
+type Option func(o *options)
+
+type options struct {
+	caps          *Capabilities
+	tapProbe      *vppTapProbe
+	defaultImpl   string
+	netNSResolver *netnsurl.Resolver
+	defaultMTU    uint32
+	underlayMTU   ifparams.UnderlayMTUFunc
+}
+
+// WithCapabilities - use caps instead of probing the host for the usable implementations
+func WithCapabilities(caps *Capabilities) Option {
+	return func(o *options) {
+		o.caps = caps
+	}
+}
+
+// WithVPPTapProbe - checks with ProbeVPPTap that the vpp behind vppagentCC is really able to create tapv2 interfaces
+//                   before selecting the tap implementation. The probe runs once, on the first Request, for all the
+//                   elements created with the option.
+func WithVPPTapProbe(vppagentCC grpc.ClientConnInterface) Option {
+	probe := &vppTapProbe{vppagentCC: vppagentCC}
+	return func(o *options) {
+		o.tapProbe = probe
+	}
+}
+
+// WithDefaultImplementation - use impl for the connections which don't request an implementation in the
+// mechanism parameters
+func WithDefaultImplementation(impl string) Option {
+	return func(o *options) {
+		o.defaultImpl = impl
+	}
+}
+
+// WithNetNSResolver - sets the resolver of the kernel mechanism NetNSURL for all the implementations
+func WithNetNSResolver(resolver *netnsurl.Resolver) Option {
+	return func(o *options) {
+		o.netNSResolver = resolver
+	}
+}
+
+// WithDefaultMTU - sets the MTU of the interfaces which mechanism doesn't have the ifparams.MTUKey parameter
+func WithDefaultMTU(mtu uint32) Option {
+	return func(o *options) {
+		o.defaultMTU = mtu
+	}
+}
+
+// WithUnderlayMTU - sets the MTU of the interfaces cross connected to a vxlan or srv6 tunnel to the MTU returned by
+//                   underlayMTU less the tunnel overhead, WithDefaultMTU takes precedence
+func WithUnderlayMTU(underlayMTU ifparams.UnderlayMTUFunc) Option {
+	return func(o *options) {
+		o.underlayMTU = underlayMTU
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		netNSResolver: netnsurl.NewResolver(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.caps == nil {
+		o.caps = ProbeCapabilities()
+	}
+	return o
+}
@@ -21,18 +21,61 @@
 package kernel
 
 import (
-	"os"
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/sirupsen/logrus"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kernelvethpair"
 )
 
+type kernelServer struct {
+	selector *selector
+	servers  map[string]networkservice.NetworkServiceServer
+}
+
 // NewServer return a NetworkServiceServer chain element that correctly handles the kernel Mechanism
-func NewServer() networkservice.NetworkServiceServer {
-	if _, err := os.Stat(vnetFilename); err == nil {
-		return kerneltap.NewServer()
+// The implementation is selected per connection: the one requested in the mechanism parameters, the one
+// set by WithDefaultImplementation or the first one supported by the host, tap being preferred over vethpair.
+// The selected implementation is recorded in the mechanism parameters under ImplementationKey.
+func NewServer(options ...Option) networkservice.NetworkServiceServer {
+	o := newOptions(options...)
+	return &kernelServer{
+		selector: newSelector(o),
+		servers: map[string]networkservice.NetworkServiceServer{
+			TapImplementation:      kerneltap.NewServer(kerneltap.WithNetNSResolver(o.netNSResolver), kerneltap.WithDefaultMTU(o.defaultMTU), kerneltap.WithUnderlayMTU(o.underlayMTU)),
+			VethPairImplementation: kernelvethpair.NewServer(kernelvethpair.WithNetNSResolver(o.netNSResolver), kernelvethpair.WithDefaultMTU(o.defaultMTU), kernelvethpair.WithUnderlayMTU(o.underlayMTU)),
+		},
+	}
+}
+
+func (k *kernelServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	mechanism := kernelMechanism(request.GetConnection().GetMechanism())
+	if mechanism == nil {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	impl, err := k.selector.selectImplementation(ctx, mechanism)
+	if err != nil {
+		return nil, err
+	}
+	setImplementation(mechanism, impl)
+	return k.servers[impl].Request(ctx, request)
+}
+
+func (k *kernelServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	mechanism := kernelMechanism(conn.GetMechanism())
+	if mechanism == nil {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	impl, err := k.selector.selectImplementation(ctx, mechanism)
+	if err != nil {
+		logrus.Errorf("can't select the kernel mechanism implementation to close connection %s: %+v", conn.GetId(), err)
+		return next.Server(ctx).Close(ctx, conn)
 	}
-	return kernelvethpair.NewServer()
+	return k.servers[impl].Close(ctx, conn)
 }
@@ -0,0 +1,185 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package kernel_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	kernelmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	netnsFileURL = "/proc/12/ns/net"
+)
+
+func kernelRequest(impl string) *networkservice.NetworkServiceRequest {
+	parameters := map[string]string{
+		kernelmech.NetNSURL: (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+	}
+	if impl != "" {
+		parameters[kernel.ImplementationKey] = impl
+	}
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:        cls.LOCAL,
+				Type:       kernelmech.MECHANISM,
+				Parameters: parameters,
+			},
+		},
+	}
+}
+
+func TestKernelServerSelectsImplementation(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := kernel.NewServer(kernel.WithCapabilities(&kernel.Capabilities{Tap: true, AfPacket: true}))
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, kernelRequest(""))
+	require.NoError(t, err)
+	require.Equal(t, kernel.TapImplementation, conn.GetMechanism().GetParameters()[kernel.ImplementationKey])
+	vppIfaces := vppagent.Config(ctx).GetVppConfig().GetInterfaces()
+	require.Len(t, vppIfaces, 1)
+	require.Equal(t, vppinterfaces.Interface_TAP, vppIfaces[0].GetType())
+
+	ctx = vppagent.WithConfig(context.Background())
+	conn, err = server.Request(ctx, kernelRequest(kernel.VethPairImplementation))
+	require.NoError(t, err)
+	require.Equal(t, kernel.VethPairImplementation, conn.GetMechanism().GetParameters()[kernel.ImplementationKey])
+	vppIfaces = vppagent.Config(ctx).GetVppConfig().GetInterfaces()
+	require.Len(t, vppIfaces, 1)
+	require.Equal(t, vppinterfaces.Interface_AF_PACKET, vppIfaces[0].GetType())
+}
+
+func TestKernelServerUnsupportedImplementation(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := kernel.NewServer(kernel.WithCapabilities(&kernel.Capabilities{AfPacket: true}))
+
+	_, err := server.Request(vppagent.WithConfig(context.Background()), kernelRequest(kernel.TapImplementation))
+	require.Error(t, err)
+
+	conn, err := server.Request(vppagent.WithConfig(context.Background()), kernelRequest(""))
+	require.NoError(t, err)
+	require.Equal(t, kernel.VethPairImplementation, conn.GetMechanism().GetParameters()[kernel.ImplementationKey])
+}
+
+func TestKernelServerRejectsAfXDP(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := kernel.NewServer(kernel.WithCapabilities(&kernel.Capabilities{Tap: true, AfPacket: true}))
+
+	_, err := server.Request(vppagent.WithConfig(context.Background()), kernelRequest("afxdp"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not supported by vpp-agent v3.1.0")
+}
+
+func TestKernelServerDefaultImplementation(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := kernel.NewServer(
+		kernel.WithCapabilities(&kernel.Capabilities{Tap: true, AfPacket: true}),
+		kernel.WithDefaultImplementation(kernel.VethPairImplementation),
+	)
+
+	conn, err := server.Request(vppagent.WithConfig(context.Background()), kernelRequest(""))
+	require.NoError(t, err)
+	require.Equal(t, kernel.VethPairImplementation, conn.GetMechanism().GetParameters()[kernel.ImplementationKey])
+}
+
+func TestKernelServerCloseUnsupportedImplementation(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := kernel.NewServer(kernel.WithCapabilities(&kernel.Capabilities{AfPacket: true}))
+
+	// The rest of the chain must still be closed even if the implementation recorded in the mechanism is gone
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Close(ctx, kernelRequest(kernel.TapImplementation).GetConnection())
+	require.NoError(t, err)
+	require.Empty(t, vppagent.Config(ctx).GetVppConfig().GetInterfaces())
+}
+
+func TestKernelServerSkipsOtherMechanisms(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := kernel.NewServer(kernel.WithCapabilities(&kernel.Capabilities{}))
+
+	request := kernelRequest(kernel.TapImplementation)
+	request.GetConnection().GetMechanism().Type = memif.MECHANISM
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, request)
+	require.NoError(t, err)
+	require.Equal(t, kernel.TapImplementation, conn.GetMechanism().GetParameters()[kernel.ImplementationKey])
+	require.Empty(t, vppagent.Config(ctx).GetVppConfig().GetInterfaces())
+	_, err = server.Close(ctx, conn)
+	require.NoError(t, err)
+}
+
+// probeCC - counts the vppagent calls of the tap probe, fails them if err is set
+type probeCC struct {
+	grpc.ClientConnInterface
+	calls int
+	err   error
+}
+
+func (p *probeCC) Invoke(context.Context, string, interface{}, interface{}, ...grpc.CallOption) error {
+	p.calls++
+	return p.err
+}
+
+func TestKernelServerProbesVPPTapOnFirstRequest(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	cc := &probeCC{}
+	server := kernel.NewServer(
+		kernel.WithCapabilities(&kernel.Capabilities{Tap: true, AfPacket: true}),
+		kernel.WithVPPTapProbe(cc),
+	)
+	require.Zero(t, cc.calls)
+
+	for i := 0; i < 2; i++ {
+		conn, err := server.Request(vppagent.WithConfig(context.Background()), kernelRequest(""))
+		require.NoError(t, err)
+		require.Equal(t, kernel.TapImplementation, conn.GetMechanism().GetParameters()[kernel.ImplementationKey])
+	}
+	// The probe tap is created and deleted once
+	require.Equal(t, 2, cc.calls)
+}
+
+func TestKernelServerFailedVPPTapProbe(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := kernel.NewServer(
+		kernel.WithCapabilities(&kernel.Capabilities{Tap: true, AfPacket: true}),
+		kernel.WithVPPTapProbe(&probeCC{err: errors.New("tap is not supported")}),
+	)
+
+	conn, err := server.Request(vppagent.WithConfig(context.Background()), kernelRequest(""))
+	require.NoError(t, err)
+	require.Equal(t, kernel.VethPairImplementation, conn.GetMechanism().GetParameters()[kernel.ImplementationKey])
+}
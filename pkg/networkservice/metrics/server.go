@@ -38,8 +38,20 @@ import (
 )
 
 type metricsServer struct {
-	executor  serialize.Executor
-	vppClient configurator.StatsPollerServiceClient
+	executor    serialize.Executor
+	vppClient   configurator.StatsPollerServiceClient
+	metricsFunc map[string]func() map[string]string
+}
+
+// Option - option for NewServer
+type Option func(s *metricsServer)
+
+// WithMetricsFunc - adds the metrics returned by metricsFunc to the path segment metrics of every connection, each
+//                   key prefixed with prefix
+func WithMetricsFunc(prefix string, metricsFunc func() map[string]string) Option {
+	return func(s *metricsServer) {
+		s.metricsFunc[prefix] = metricsFunc
+	}
 }
 
 func (s *metricsServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
@@ -48,22 +60,42 @@ func (s *metricsServer) Request(ctx context.Context, request *networkservice.Net
 		return nil, errors.New("VPPAgent config is missing")
 	}
 
+	index := request.GetConnection().GetPath().GetIndex()
 	ifaces := conf.GetVppConfig().GetInterfaces()
 	if len(ifaces) == 0 {
 		log.Entry(ctx).Warn("vppconfig has no interfaces")
-		return next.Server(ctx).Request(ctx, request)
+		conn, err := next.Server(ctx).Request(ctx, request)
+		if err == nil {
+			s.addMetrics(conn, index)
+		}
+		return conn, err
 	}
 
-	index := request.GetConnection().GetPath().GetIndex()
 	conn, err := next.Server(ctx).Request(ctx, request)
 	if err == nil {
 		<-s.executor.AsyncExec(func() {
 			s.retieveVppStats(ctx, conn, index, ifaces)
 		})
+		s.addMetrics(conn, index)
 	}
 	return conn, err
 }
 
+func (s *metricsServer) addMetrics(conn *networkservice.Connection, index uint32) {
+	if len(s.metricsFunc) == 0 || int(index) >= len(conn.GetPath().GetPathSegments()) {
+		return
+	}
+	segment := conn.GetPath().GetPathSegments()[index]
+	if segment.Metrics == nil {
+		segment.Metrics = make(map[string]string)
+	}
+	for prefix, metricsFunc := range s.metricsFunc {
+		for k, v := range metricsFunc() {
+			segment.Metrics[prefix+k] = v
+		}
+	}
+}
+
 func (s *metricsServer) retieveVppStats(ctx context.Context, conn *networkservice.Connection, index uint32, ifaces []*vpp_interfaces.Interface) {
 	trace.Log(ctx).Debugf("MetricsServer: Request Metrics")
 	req := &configurator.PollStatsRequest{
@@ -98,9 +130,13 @@ func (s *metricsServer) Close(ctx context.Context, conn *networkservice.Connecti
 }
 
 // NewServer creates a new metrics collector instance
-func NewServer(vppClient configurator.StatsPollerServiceClient) networkservice.NetworkServiceServer {
+func NewServer(vppClient configurator.StatsPollerServiceClient, options ...Option) networkservice.NetworkServiceServer {
 	rv := &metricsServer{
-		vppClient: vppClient,
+		vppClient:   vppClient,
+		metricsFunc: map[string]func() map[string]string{},
+	}
+	for _, option := range options {
+		option(rv)
 	}
 	return rv
 }
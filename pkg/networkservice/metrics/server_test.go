@@ -99,6 +99,16 @@ func TestMonitorVppEvents(t *testing.T) {
 	require.NotNil(t, client.stream.ctx.Err())
 }
 
+func TestMetricsFunc(t *testing.T) {
+	server := metrics.NewServer(&testClient{}, metrics.WithMetricsFunc("netns_", func() map[string]string {
+		return map[string]string{"lookups": "3"}
+	}))
+
+	response, err := server.Request(vppagent.WithConfig(context.Background()), newRequest())
+	require.NoError(t, err)
+	require.Equal(t, "3", response.GetPath().GetPathSegments()[0].GetMetrics()["netns_lookups"])
+}
+
 func newRequest() *networkservice.NetworkServiceRequest {
 	return &networkservice.NetworkServiceRequest{
 		Connection: &networkservice.Connection{
@@ -0,0 +1,115 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vppagent
+
+import (
+	"context"
+	"sync"
+
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+)
+
+// CommitFunc - puts the current state of an object shared by several connections, like a bridge domain, into the
+//              configs committed for a connection
+type CommitFunc func(configs *CommitConfigs)
+
+// CommitConfigs - configs committed for a connection: the config of the connection is the update config on Request
+//                 and the remove config on Close, the other one is created only if a CommitFunc asks for it
+type CommitConfigs struct {
+	update *configurator.Config
+	remove *configurator.Config
+}
+
+// Update - returns the config sent with Update
+func (c *CommitConfigs) Update() *configurator.Config {
+	if c.update == nil {
+		c.update = newConfig()
+	}
+	return c.update
+}
+
+// Remove - returns the config sent with Delete
+func (c *CommitConfigs) Remove() *configurator.Config {
+	if c.remove == nil {
+		c.remove = newConfig()
+	}
+	return c.remove
+}
+
+type commitFuncs struct {
+	funcs []CommitFunc
+	after []func()
+	mu    sync.Mutex
+}
+
+// commitMutex - serializes the commits putting the state of shared objects into the configs
+var commitMutex sync.Mutex
+
+// OnCommit - registers f to be called for the config of ctx right before it is committed
+func OnCommit(ctx context.Context, f CommitFunc) {
+	if c, ok := ctx.Value(commitFuncsKey).(*commitFuncs); ok {
+		c.mu.Lock()
+		c.funcs = append(c.funcs, f)
+		c.mu.Unlock()
+	}
+}
+
+// AfterCommit - registers f to be called right after the config of ctx is sent, whether it is sent successfully or not.
+//               f is called at once if ctx isn't committed.
+func AfterCommit(ctx context.Context, f func()) {
+	if c, ok := ctx.Value(commitFuncsKey).(*commitFuncs); ok {
+		c.mu.Lock()
+		c.after = append(c.after, f)
+		c.mu.Unlock()
+		return
+	}
+	f()
+}
+
+// Commit - calls the CommitFuncs registered for ctx and sends the configs with send, the remove config on Request and
+//          the update config on Close are nil unless a CommitFunc uses them.  The commits with CommitFuncs are
+//          serialized, so the states of the shared objects are sent in the order they are taken and a stale state
+//          can never be sent last.
+func Commit(ctx context.Context, isClose bool, send func(update, remove *configurator.Config) error) error {
+	configs := &CommitConfigs{}
+	if isClose {
+		configs.remove = Config(ctx)
+	} else {
+		configs.update = Config(ctx)
+	}
+	var funcs []CommitFunc
+	var after []func()
+	if c, ok := ctx.Value(commitFuncsKey).(*commitFuncs); ok {
+		c.mu.Lock()
+		funcs, after = c.funcs, c.after
+		c.funcs, c.after = nil, nil
+		c.mu.Unlock()
+	}
+	defer func() {
+		for _, f := range after {
+			f()
+		}
+	}()
+	if len(funcs) > 0 {
+		commitMutex.Lock()
+		defer commitMutex.Unlock()
+	}
+	for _, f := range funcs {
+		f(configs)
+	}
+	return send(configs.update, configs.remove)
+}
@@ -29,7 +29,8 @@ import (
 type contextKeyType string
 
 const (
-	configKey contextKeyType = "configKey"
+	configKey      contextKeyType = "configKey"
+	commitFuncsKey contextKeyType = "commitFuncsKey"
 )
 
 // WithConfig returns a context that contains a vppagent config
@@ -37,12 +38,16 @@ func WithConfig(ctx context.Context) context.Context {
 	if config, ok := ctx.Value(configKey).(*configurator.Config); ok && config != nil {
 		return ctx
 	}
-	rv := &configurator.Config{
+	ctx = context.WithValue(ctx, commitFuncsKey, &commitFuncs{})
+	return context.WithValue(ctx, configKey, newConfig())
+}
+
+func newConfig() *configurator.Config {
+	return &configurator.Config{
 		VppConfig:      &vpp.ConfigData{},
 		LinuxConfig:    &linux.ConfigData{},
 		NetallocConfig: &netalloc.ConfigData{},
 	}
-	return context.WithValue(ctx, configKey, rv)
 }
 
 // Config - returns the vppagent *configurator.Config stored in ctx
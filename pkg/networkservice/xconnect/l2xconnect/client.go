@@ -22,6 +22,7 @@ import (
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
 	l2 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l2"
 	"google.golang.org/grpc"
 
@@ -60,8 +61,8 @@ func (l *l2XconnectClient) Close(ctx context.Context, conn *networkservice.Conne
 }
 
 func (l *l2XconnectClient) appendL2XConnect(conf *configurator.Config) {
-	if len(conf.GetVppConfig().GetInterfaces()) >= 2 {
-		ifaces := conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().Interfaces)-2:]
+	if ifaces := xconnectInterfaces(conf.GetVppConfig().GetInterfaces()); len(ifaces) >= 2 {
+		ifaces = ifaces[len(ifaces)-2:]
 		conf.GetVppConfig().XconnectPairs = append(conf.GetVppConfig().XconnectPairs,
 			&l2.XConnectPair{
 				ReceiveInterface:  ifaces[0].Name,
@@ -73,3 +74,21 @@ func (l *l2XconnectClient) appendL2XConnect(conf *configurator.Config) {
 			})
 	}
 }
+
+// xconnectInterfaces - returns the interfaces without the parents of sub-interfaces, the sub-interface is
+// cross connected instead of its parent
+func xconnectInterfaces(interfaces []*vppinterfaces.Interface) []*vppinterfaces.Interface {
+	parents := make(map[string]bool)
+	for _, iface := range interfaces {
+		if sub := iface.GetSub(); sub != nil {
+			parents[sub.GetParentName()] = true
+		}
+	}
+	var rv []*vppinterfaces.Interface
+	for _, iface := range interfaces {
+		if !parents[iface.GetName()] {
+			rv = append(rv, iface)
+		}
+	}
+	return rv
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+// Package ifnames provides stable, collision-free names for the Linux interfaces created for connections
+package ifnames
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	separator       = "-"
+	minHashLength   = 8
+	defaultIfPrefix = "nsm"
+)
+
+// Generate - returns a name of at most kernel.LinuxIfMaxLength chars made of the prefix and a hash of the id.
+//            The same prefix and id always give the same name.  The prefix is truncated to leave room for
+//            at least 8 hex digits of the hash.
+func Generate(prefix, id string) string {
+	hash := hashOf(id)
+	if prefix == "" {
+		return hash[:kernel.LinuxIfMaxLength]
+	}
+	if maxPrefixLength := kernel.LinuxIfMaxLength - len(separator) - minHashLength; len(prefix) > maxPrefixLength {
+		prefix = prefix[:maxPrefixLength]
+	}
+	return prefix + separator + hash[:kernel.LinuxIfMaxLength-len(prefix)-len(separator)]
+}
+
+// IsGenerated - returns true if name is the one Generate returns for id with some prefix
+func IsGenerated(name, id string) bool {
+	hash := hashOf(id)
+	if len(name) == kernel.LinuxIfMaxLength && name == hash[:kernel.LinuxIfMaxLength] {
+		return true
+	}
+	i := strings.LastIndex(name, separator)
+	if i < 0 || len(name)-i-len(separator) < minHashLength {
+		return false
+	}
+	return name == Generate(name[:i], id)
+}
+
+func hashOf(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// ForConnection - returns the name of the interface requested in the mechanism parameters truncated to
+//                 kernel.LinuxIfMaxLength chars, or a name generated from the network service and the connection id
+//                 if no name is requested
+func ForConnection(mechanism *kernel.Mechanism, conn *networkservice.Connection) string {
+	if name, ok := mechanism.GetParameters()[kernel.InterfaceNameKey]; ok && name != "" {
+		if len(name) > kernel.LinuxIfMaxLength {
+			return name[:kernel.LinuxIfMaxLength]
+		}
+		return name
+	}
+	prefix := conn.GetNetworkService()
+	if prefix == "" {
+		prefix = defaultIfPrefix
+	}
+	return Generate(prefix, conn.GetId())
+}
+
+type nameKey struct {
+	netns string
+	name  string
+}
+
+// Registry - keeps track of the interface names used by connections in each network namespace
+type Registry struct {
+	exists func(netnsFilename, name string) (bool, error)
+	owners map[nameKey]string
+	mu     sync.Mutex
+}
+
+// Option - option for NewRegistry
+type Option func(r *Registry)
+
+// WithExistsFunc - sets the function used to check if an interface with the name exists in the network namespace,
+//                  by default the network namespace is entered and the interface is looked up by name
+func WithExistsFunc(exists func(netnsFilename, name string) (bool, error)) Option {
+	return func(r *Registry) {
+		r.exists = exists
+	}
+}
+
+// processRegistry - the names are unique per network namespace whatever element creates the interfaces, so the
+// elements of the process share a single Registry
+var processRegistry = NewRegistry()
+
+// ProcessRegistry - returns the Registry shared by the process
+func ProcessRegistry() *Registry {
+	return processRegistry
+}
+
+// NewRegistry - creates a new Registry
+func NewRegistry(options ...Option) *Registry {
+	r := &Registry{
+		exists: interfaceExists,
+		owners: map[nameKey]string{},
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// Reserve - reserves the name in the network namespace file netnsFilename ("" for the current network namespace)
+//           for the connection id, whatever path of the network namespace netnsFilename is.  Reserving a name already reserved by the same connection does nothing.
+//           An error is returned if the name is reserved by another connection or if an interface with this name
+//           already exists in the network namespace, unless the name is generated for id (see IsGenerated): such an
+//           interface is the one created for the connection before the names were lost, e.g. by a restart, so it is
+//           taken over on heal or refresh.
+func (r *Registry) Reserve(netnsFilename, name, id string) error {
+	key := nameKey{netns: netnsID(netnsFilename), name: name}
+
+	r.mu.Lock()
+	if owner, ok := r.owners[key]; ok {
+		r.mu.Unlock()
+		if owner != id {
+			return errors.Errorf("interface name %q in netns %q is already used by connection %q", name, netnsFilename, owner)
+		}
+		return nil
+	}
+	// The name is reserved before the check, so no concurrent Request can pass the check with it until the interface
+	// exists. The check enters the netns, so it runs unlocked.
+	r.owners[key] = id
+	r.mu.Unlock()
+
+	exists, err := r.exists(netnsFilename, name)
+	if err != nil {
+		// vpp-agent reports the real problem if the netns is unusable, so we don't fail here
+		logrus.Warnf("can't check if interface %q exists in netns %q: %v", name, netnsFilename, err)
+	}
+	if exists && !IsGenerated(name, id) {
+		r.mu.Lock()
+		if r.owners[key] == id {
+			delete(r.owners, key)
+		}
+		r.mu.Unlock()
+		return errors.Errorf("interface name %q collides with an existing interface in netns %q", name, netnsFilename)
+	}
+	return nil
+}
+
+// Release - releases all the names reserved for the connection id
+func (r *Registry) Release(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, owner := range r.owners {
+		if owner == id {
+			delete(r.owners, key)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package ifnames_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifnames"
+)
+
+const (
+	netnsFilename = "/proc/12/ns/net"
+)
+
+func TestGenerate(t *testing.T) {
+	first := ifnames.Generate("server", "server-2cbe0e4c-8d4f-4b2c-a6ee-2a1d5b3c7f10")
+	second := ifnames.Generate("server", "server-2cbe0e4c-8d4f-4b2c-a6ee-2a1d5b3c7f11")
+	assert.LessOrEqual(t, len(first), kernel.LinuxIfMaxLength)
+	assert.LessOrEqual(t, len(second), kernel.LinuxIfMaxLength)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, first, ifnames.Generate("server", "server-2cbe0e4c-8d4f-4b2c-a6ee-2a1d5b3c7f10"))
+	assert.Regexp(t, "^server-[0-9a-f]{8}$", first)
+
+	assert.Regexp(t, "^long-n-[0-9a-f]{8}$", ifnames.Generate("long-network-service", "id"))
+	assert.Regexp(t, "^ns-[0-9a-f]{12}$", ifnames.Generate("ns", "id"))
+	assert.Regexp(t, "^[0-9a-f]{15}$", ifnames.Generate("", "id"))
+}
+
+func TestIsGenerated(t *testing.T) {
+	for _, prefix := range []string{"", "ns", "server", "long-network-service"} {
+		assert.True(t, ifnames.IsGenerated(ifnames.Generate(prefix, "id"), "id"), prefix)
+		assert.False(t, ifnames.IsGenerated(ifnames.Generate(prefix, "id"), "other-id"), prefix)
+	}
+	assert.False(t, ifnames.IsGenerated("eth0", "id"))
+	assert.False(t, ifnames.IsGenerated("nsm-", "id"))
+}
+
+func TestForConnection(t *testing.T) {
+	conn := &networkservice.Connection{
+		Id:             "conn-1",
+		NetworkService: "icmp-responder",
+	}
+	mechanism := kernel.ToMechanism(&networkservice.Mechanism{
+		Cls:        cls.LOCAL,
+		Type:       kernel.MECHANISM,
+		Parameters: map[string]string{},
+	})
+	assert.Equal(t, ifnames.Generate("icmp-responder", "conn-1"), ifnames.ForConnection(mechanism, conn))
+
+	mechanism.GetParameters()[kernel.InterfaceNameKey] = "nsm-very-long-interface"
+	assert.Equal(t, "nsm-very-long-i", ifnames.ForConnection(mechanism, conn))
+}
+
+func TestRegistryCollisions(t *testing.T) {
+	existing := map[string]bool{"eth0": true}
+	registry := ifnames.NewRegistry(ifnames.WithExistsFunc(func(netns, name string) (bool, error) {
+		assert.Equal(t, netnsFilename, netns)
+		return existing[name], nil
+	}))
+
+	require.NoError(t, registry.Reserve(netnsFilename, "nsm0", "conn-1"))
+	// Refresh of the same connection
+	existing["nsm0"] = true
+	require.NoError(t, registry.Reserve(netnsFilename, "nsm0", "conn-1"))
+
+	require.Error(t, registry.Reserve(netnsFilename, "nsm0", "conn-2"))
+	require.Error(t, registry.Reserve(netnsFilename, "eth0", "conn-2"))
+
+	registry.Release("conn-1")
+	existing["nsm0"] = false
+	require.NoError(t, registry.Reserve(netnsFilename, "nsm0", "conn-2"))
+}
+
+func TestRegistryTakesOverGeneratedNames(t *testing.T) {
+	name := ifnames.Generate("icmp-responder", "conn-1")
+	// The interface was created for conn-1 before a restart emptied the registry
+	registry := ifnames.NewRegistry(ifnames.WithExistsFunc(func(netns, ifName string) (bool, error) {
+		return ifName == name, nil
+	}))
+
+	require.Error(t, registry.Reserve(netnsFilename, name, "conn-2"))
+	require.NoError(t, registry.Reserve(netnsFilename, name, "conn-1"))
+	require.Error(t, registry.Reserve(netnsFilename, name, "conn-2"))
+}
+
+func TestRegistryReservesBeforeExistsCheck(t *testing.T) {
+	checking := make(chan struct{})
+	checked := make(chan struct{})
+	registry := ifnames.NewRegistry(ifnames.WithExistsFunc(func(netns, name string) (bool, error) {
+		close(checking)
+		<-checked
+		return false, nil
+	}))
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- registry.Reserve(netnsFilename, "nsm0", "conn-1")
+	}()
+	<-checking
+	// The interface of conn-1 doesn't exist yet, but its name is already taken
+	require.Error(t, registry.Reserve(netnsFilename, "nsm0", "conn-2"))
+	close(checked)
+	require.NoError(t, <-errCh)
+}
+
+func TestRegistryKeysByNetNSInode(t *testing.T) {
+	registry := ifnames.NewRegistry(ifnames.WithExistsFunc(func(netns, name string) (bool, error) {
+		return false, nil
+	}))
+	require.NoError(t, registry.Reserve("/proc/self/ns/net", "nsm0", "conn-1"))
+	// Another path of the same network namespace, like the one pinned for another connection
+	require.Error(t, registry.Reserve(fmt.Sprintf("/proc/%d/ns/net", os.Getpid()), "nsm0", "conn-2"))
+}
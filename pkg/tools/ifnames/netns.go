@@ -0,0 +1,68 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package ifnames
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsexec"
+)
+
+// interfaceExists - checks if the interface exists in the network namespace referenced by netnsFilename
+func interfaceExists(netnsFilename, name string) (exists bool, err error) {
+	err = netnsexec.Do(netnsFilename, func() error {
+		exists, err = lookup(name)
+		return err
+	})
+	return exists, err
+}
+
+func lookup(name string) (bool, error) {
+	if _, err := net.InterfaceByName(name); err != nil {
+		if strings.Contains(err.Error(), "no such network interface") {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "can't look up interface %s", name)
+	}
+	return true, nil
+}
+
+// netnsID - identifies the network namespace by the device and inode of netnsFilename, so the different paths of the
+// same network namespace, e.g. the paths pinned for each connection, give the same id. The current network namespace
+// is "", a file which can't be stat'ed is identified by its path.
+func netnsID(netnsFilename string) string {
+	if netnsFilename == "" {
+		return ""
+	}
+	info, err := os.Stat(netnsFilename)
+	if err != nil {
+		return netnsFilename
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return netnsFilename
+	}
+	return fmt.Sprintf("%d:%d", stat.Dev, stat.Ino)
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ifparams provides kernel mechanism parameters tuning the interfaces created for a connection
+package ifparams
+
+import (
+	"strconv"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/pkg/errors"
+)
+
+const (
+	// MTUKey - MTU of both the vpp and the Linux side of the interface, 0 or unset leaves the default
+	MTUKey = "mtu"
+	// RxRingSizeKey - size of the rx ring of the vpp tap interface, a power of two not above MaxRingSize
+	RxRingSizeKey = "rxRingSize"
+	// TxRingSizeKey - size of the tx ring of the vpp tap interface, a power of two not above MaxRingSize
+	TxRingSizeKey = "txRingSize"
+	// GSOKey - "true" enables generic segmentation offload on the vpp tap interface
+	GSOKey = "gso"
+	// ChecksumOffloadKey - "true" enables rx and tx checksum offloading on both ends of the veth pair
+	ChecksumOffloadKey = "checksumOffload"
+	// QueuesKey - number of rx/tx queue pairs of the interface, vpp-agent v3.1.0 can configure neither tap nor
+	//             af_packet queues, so only 1 is accepted
+	QueuesKey = "queues"
+
+	// MaxRingSize - the largest ring size vpp accepts for tap interfaces
+	MaxRingSize = 32768
+)
+
+// Params - interface parameters parsed from the kernel mechanism
+type Params struct {
+	MTU             uint32
+	RxRingSize      uint32
+	TxRingSize      uint32
+	GSO             bool
+	ChecksumOffload bool
+}
+
+// FromMechanism - parses the interface parameters of the mechanism, unset parameters have zero values
+func FromMechanism(mechanism *kernel.Mechanism) (*Params, error) {
+	params := mechanism.GetParameters()
+	rv := &Params{}
+	var err error
+	if rv.MTU, err = parseUint32(params, MTUKey); err != nil {
+		return nil, err
+	}
+	if rv.RxRingSize, err = parseUint32(params, RxRingSizeKey); err != nil {
+		return nil, err
+	}
+	if rv.TxRingSize, err = parseUint32(params, TxRingSizeKey); err != nil {
+		return nil, err
+	}
+	if err = checkRingSize(RxRingSizeKey, rv.RxRingSize); err != nil {
+		return nil, err
+	}
+	if err = checkRingSize(TxRingSizeKey, rv.TxRingSize); err != nil {
+		return nil, err
+	}
+	if rv.GSO, err = parseBool(params, GSOKey); err != nil {
+		return nil, err
+	}
+	if rv.ChecksumOffload, err = parseBool(params, ChecksumOffloadKey); err != nil {
+		return nil, err
+	}
+	queues, err := parseUint32(params, QueuesKey)
+	if err != nil {
+		return nil, err
+	}
+	if queues > 1 {
+		return nil, errors.Errorf("unsupported kernel mechanism parameter %s: %d, only a single queue can be configured", QueuesKey, queues)
+	}
+	return rv, nil
+}
+
+// Reject - returns an error if any of keys is set in the parameters of the mechanism, the implementation impl
+//          uses it for the parameters it is not able to apply
+func Reject(mechanism *kernel.Mechanism, impl string, keys ...string) error {
+	for _, key := range keys {
+		if value := mechanism.GetParameters()[key]; value != "" {
+			return errors.Errorf("kernel mechanism parameter %s: %q is not supported by the %s implementation", key, value, impl)
+		}
+	}
+	return nil
+}
+
+func checkRingSize(key string, size uint32) error {
+	if size == 0 {
+		return nil
+	}
+	if size > MaxRingSize || size&(size-1) != 0 {
+		return errors.Errorf("invalid kernel mechanism parameter %s: %d, must be a power of two not above %d", key, size, MaxRingSize)
+	}
+	return nil
+}
+
+func parseUint32(params map[string]string, key string) (uint32, error) {
+	value, ok := params[key]
+	if !ok || value == "" {
+		return 0, nil
+	}
+	rv, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid kernel mechanism parameter %s: %q", key, value)
+	}
+	return uint32(rv), nil
+}
+
+func parseBool(params map[string]string, key string) (bool, error) {
+	value, ok := params[key]
+	if !ok || value == "" {
+		return false, nil
+	}
+	rv, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid kernel mechanism parameter %s: %q", key, value)
+	}
+	return rv, nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ifparams
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/srv6"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/vxlan"
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	"google.golang.org/grpc"
+)
+
+const (
+	ethernetHeaderLength = 14
+	ipv4HeaderLength     = 20
+	ipv6HeaderLength     = 40
+	udpHeaderLength      = 8
+	vxlanHeaderLength    = 8
+	// srhLength - segment routing header with the two segments the srv6 mechanism steers through
+	srhLength = 8 + 2*net.IPv6len
+)
+
+// TunnelOverhead - returns the number of bytes the encapsulation of the tunnel mechanism mechanismType adds to the
+//                  IP packets of a kernel interface cross connected to it, the inner Ethernet header included.
+//                  tunnelIP selects the outer IP version of the vxlan mechanism, srv6 is always IPv6.
+func TunnelOverhead(mechanismType string, tunnelIP net.IP) (uint32, error) {
+	switch mechanismType {
+	case vxlan.MECHANISM:
+		if tunnelIP.To4() != nil {
+			return ethernetHeaderLength + vxlanHeaderLength + udpHeaderLength + ipv4HeaderLength, nil
+		}
+		return ethernetHeaderLength + vxlanHeaderLength + udpHeaderLength + ipv6HeaderLength, nil
+	case srv6.MECHANISM:
+		return ethernetHeaderLength + srhLength + ipv6HeaderLength, nil
+	}
+	return 0, errors.Errorf("unknown tunnel mechanism %s", mechanismType)
+}
+
+// ConfigTunnelOverhead - returns the encapsulation overhead of the tunnel the vxlan or srv6 mechanism puts into conf,
+//                        0 if conf has no tunnel, e.g. the other side of the cross connect is a local memif
+func ConfigTunnelOverhead(conf *configurator.Config) uint32 {
+	vppConfig := conf.GetVppConfig()
+	for _, iface := range vppConfig.GetInterfaces() {
+		if iface.GetType() == vppinterfaces.Interface_VXLAN_TUNNEL {
+			overhead, _ := TunnelOverhead(vxlan.MECHANISM, net.ParseIP(iface.GetVxlan().GetSrcAddress()))
+			return overhead
+		}
+	}
+	if len(vppConfig.GetSrv6Localsids()) > 0 || len(vppConfig.GetSrv6Policies()) > 0 {
+		overhead, _ := TunnelOverhead(srv6.MECHANISM, nil)
+		return overhead
+	}
+	return 0
+}
+
+// SetTunnelMTU - sets the MTU of the vpp and Linux interfaces of conf named names, so their packets fit into the
+//                underlay after the encapsulation of the tunnel in conf. conf without a tunnel is left alone.
+func SetTunnelMTU(conf *configurator.Config, underlayMTU uint32, names ...string) error {
+	overhead := ConfigTunnelOverhead(conf)
+	if overhead == 0 {
+		return nil
+	}
+	if underlayMTU <= overhead {
+		return errors.Errorf("underlay MTU %d is too small for the tunnel overhead %d", underlayMTU, overhead)
+	}
+	isNamed := make(map[string]bool, len(names))
+	for _, name := range names {
+		isNamed[name] = true
+	}
+	for _, iface := range conf.GetVppConfig().GetInterfaces() {
+		if isNamed[iface.GetName()] {
+			iface.Mtu = underlayMTU - overhead
+		}
+	}
+	for _, iface := range conf.GetLinuxConfig().GetInterfaces() {
+		if isNamed[iface.GetName()] {
+			iface.Mtu = underlayMTU - overhead
+		}
+	}
+	return nil
+}
+
+// UnderlayMTUFunc - returns the MTU of the underlay the tunnels go through
+type UnderlayMTUFunc func(ctx context.Context) (uint32, error)
+
+// StaticUnderlayMTU - returns UnderlayMTUFunc returning mtu
+func StaticUnderlayMTU(mtu uint32) UnderlayMTUFunc {
+	return func(context.Context) (uint32, error) {
+		return mtu, nil
+	}
+}
+
+// VPPUnderlayMTU - returns UnderlayMTUFunc reading the MTU of the vpp interface having the tunnelIP address from the
+//                  vpp-agent Dump. The MTU is read once, a failed read is retried on the next call.
+func VPPUnderlayMTU(vppagentCC grpc.ClientConnInterface, tunnelIP net.IP) UnderlayMTUFunc {
+	client := configurator.NewConfiguratorServiceClient(vppagentCC)
+	var mtu uint32
+	var mu sync.Mutex
+	return func(ctx context.Context) (uint32, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if mtu != 0 {
+			return mtu, nil
+		}
+		dump, err := client.Dump(ctx, &configurator.DumpRequest{})
+		if err != nil {
+			return 0, errors.Wrap(err, "error during ConfiguratorClient.Dump")
+		}
+		for _, iface := range dump.GetDump().GetVppConfig().GetInterfaces() {
+			if hasIP(iface.GetIpAddresses(), tunnelIP) {
+				if iface.GetMtu() == 0 {
+					return 0, errors.Errorf("vpp interface %s with the tunnel IP %s has no MTU", iface.GetName(), tunnelIP)
+				}
+				mtu = iface.GetMtu()
+				return mtu, nil
+			}
+		}
+		return 0, errors.Errorf("no vpp interface has the tunnel IP %s", tunnelIP)
+	}
+}
+
+func hasIP(addresses []string, ip net.IP) bool {
+	for _, addr := range addresses {
+		addrIP, _, err := net.ParseCIDR(addr)
+		if err != nil {
+			addrIP = net.ParseIP(addr)
+		}
+		if addrIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
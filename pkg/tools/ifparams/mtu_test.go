@@ -0,0 +1,120 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ifparams_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/srv6"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/vxlan"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifparams"
+)
+
+func TestTunnelOverhead(t *testing.T) {
+	overhead, err := ifparams.TunnelOverhead(vxlan.MECHANISM, net.ParseIP("10.0.0.1"))
+	require.NoError(t, err)
+	require.Equal(t, uint32(50), overhead)
+
+	overhead, err = ifparams.TunnelOverhead(vxlan.MECHANISM, net.ParseIP("fd00::1"))
+	require.NoError(t, err)
+	require.Equal(t, uint32(70), overhead)
+
+	overhead, err = ifparams.TunnelOverhead(srv6.MECHANISM, net.ParseIP("10.0.0.1"))
+	require.NoError(t, err)
+	require.Equal(t, uint32(94), overhead)
+
+	_, err = ifparams.TunnelOverhead("MEMIF", nil)
+	require.Error(t, err)
+}
+
+func TestSetTunnelMTU(t *testing.T) {
+	conf := &configurator.Config{
+		VppConfig: &vpp.ConfigData{
+			Interfaces: []*vppinterfaces.Interface{
+				{Name: "server-conn"},
+				{
+					Name: "client-conn",
+					Type: vppinterfaces.Interface_VXLAN_TUNNEL,
+					Link: &vppinterfaces.Interface_Vxlan{
+						Vxlan: &vppinterfaces.VxlanLink{SrcAddress: "10.0.0.1", DstAddress: "10.0.0.2"},
+					},
+				},
+			},
+		},
+		LinuxConfig: &linux.ConfigData{
+			Interfaces: []*linuxinterfaces.Interface{{Name: "server-conn"}},
+		},
+	}
+	require.NoError(t, ifparams.SetTunnelMTU(conf, 1500, "server-conn"))
+	require.Equal(t, uint32(1450), conf.GetVppConfig().GetInterfaces()[0].GetMtu())
+	require.Equal(t, uint32(0), conf.GetVppConfig().GetInterfaces()[1].GetMtu())
+	require.Equal(t, uint32(1450), conf.GetLinuxConfig().GetInterfaces()[0].GetMtu())
+
+	require.Error(t, ifparams.SetTunnelMTU(conf, 50, "server-conn"))
+
+	// No tunnel, e.g. memif on the other side
+	conf.GetVppConfig().GetInterfaces()[1].Type = vppinterfaces.Interface_MEMIF
+	conf.GetVppConfig().GetInterfaces()[0].Mtu = 0
+	require.NoError(t, ifparams.SetTunnelMTU(conf, 1500, "server-conn"))
+	require.Equal(t, uint32(0), conf.GetVppConfig().GetInterfaces()[0].GetMtu())
+}
+
+type dumpCC struct {
+	grpc.ClientConnInterface
+	calls int
+	dump  *configurator.Config
+}
+
+func (d *dumpCC) Invoke(_ context.Context, _ string, _, reply interface{}, _ ...grpc.CallOption) error {
+	d.calls++
+	reply.(*configurator.DumpResponse).Dump = d.dump
+	return nil
+}
+
+func TestVPPUnderlayMTU(t *testing.T) {
+	cc := &dumpCC{
+		dump: &configurator.Config{
+			VppConfig: &vpp.ConfigData{
+				Interfaces: []*vppinterfaces.Interface{
+					{Name: "loop0", Mtu: 9000, IpAddresses: []string{"192.0.2.1/24"}},
+					{Name: "uplink", Mtu: 1500, IpAddresses: []string{"10.0.0.1/24"}},
+				},
+			},
+		},
+	}
+	underlayMTU := ifparams.VPPUnderlayMTU(cc, net.ParseIP("10.0.0.1"))
+	mtu, err := underlayMTU(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint32(1500), mtu)
+	// The MTU is cached
+	_, err = underlayMTU(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, cc.calls)
+
+	_, err = ifparams.VPPUnderlayMTU(cc, net.ParseIP("10.0.0.2"))(context.Background())
+	require.Error(t, err)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build linux
+
+// Package netnsexec provides a way to run code inside of a network namespace
+package netnsexec
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// network namespace of the current thread, the process may have threads in other namespaces
+const currentNetNSFilename = "/proc/self/task/%d/ns/net"
+
+// Do - calls fn on a locked OS thread entered into the network namespace referenced by netnsFilename,
+//      the thread is returned to its original network namespace after fn returns.
+//      fn is called in the current network namespace if netnsFilename is empty.
+func Do(netnsFilename string, fn func() error) error {
+	if netnsFilename == "" {
+		return fn()
+	}
+	target, err := os.Open(netnsFilename)
+	if err != nil {
+		return errors.Wrapf(err, "can't open netns file %s", netnsFilename)
+	}
+	defer func() { _ = target.Close() }()
+
+	runtime.LockOSThread()
+	current, err := os.Open(fmt.Sprintf(currentNetNSFilename, unix.Gettid()))
+	if err != nil {
+		runtime.UnlockOSThread()
+		return errors.Wrap(err, "can't open current netns file")
+	}
+	defer func() { _ = current.Close() }()
+
+	if err = unix.Setns(int(target.Fd()), unix.CLONE_NEWNET); err != nil {
+		runtime.UnlockOSThread()
+		return errors.Wrapf(err, "can't enter netns %s", netnsFilename)
+	}
+	fnErr := fn()
+	if err = unix.Setns(int(current.Fd()), unix.CLONE_NEWNET); err != nil {
+		// The thread is left locked, so it is terminated instead of being reused in a wrong netns
+		return errors.Wrap(err, "can't return to the original netns")
+	}
+	runtime.UnlockOSThread()
+	return fnErr
+}
+
+// ThreadProcDir - returns the /proc directory of the current OS thread, its net subdirectory describes
+//                 the network namespace of the thread rather than of the process
+func ThreadProcDir() string {
+	return fmt.Sprintf("/proc/self/task/%d", unix.Gettid())
+}
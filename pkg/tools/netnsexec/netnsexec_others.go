@@ -0,0 +1,37 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !linux,!windows
+
+// Package netnsexec provides a way to run code inside of a network namespace
+package netnsexec
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Do - network namespaces are supported only on Linux, so fn is called only if netnsFilename is empty
+func Do(netnsFilename string, fn func() error) error {
+	if netnsFilename == "" {
+		return fn()
+	}
+	return errors.New("network namespaces are supported only on Linux")
+}
+
+// ThreadProcDir - returns the /proc directory of the current process
+func ThreadProcDir() string {
+	return "/proc/self"
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netnsinode
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	procDir = "/proc"
+
+	lookupsKey = "lookups"
+	missesKey  = "misses"
+)
+
+// network namespace bind mounts made by `ip netns add` and container runtimes
+var defaultNetNSDirs = []string{"/var/run/netns", "/run/netns"}
+
+// Resolver - resolves network namespace inodes to network namespace files. Resolved files are cached, the cache is
+//            refreshed on misses and, if Watch is running, periodically.
+type Resolver struct {
+	netNSDirs []string
+	paths     map[uint64]string
+	lookups   uint
+	misses    uint
+	mu        sync.Mutex
+}
+
+// Option - option for NewResolver
+type Option func(r *Resolver)
+
+// WithNetNSDirs - sets the directories with bind-mounted network namespace files, by default /var/run/netns and
+//                 /run/netns
+func WithNetNSDirs(dirs ...string) Option {
+	return func(r *Resolver) {
+		r.netNSDirs = dirs
+	}
+}
+
+// NewResolver - creates a new Resolver
+func NewResolver(options ...Option) *Resolver {
+	r := &Resolver{
+		netNSDirs: defaultNetNSDirs,
+		paths:     map[uint64]string{},
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// Resolve - returns a file of the network namespace with the inode. Bind-mounted network namespace files are
+//           preferred as they don't go away with a process.
+func (r *Resolver) Resolve(inode uint64) (string, error) {
+	r.mu.Lock()
+	r.lookups++
+	filename, ok := r.paths[inode]
+	r.mu.Unlock()
+
+	// The process may be gone or its pid reused since the file was cached
+	if ok {
+		if tryInode, err := getInode(filename); err == nil && tryInode == inode {
+			return filename, nil
+		}
+	}
+
+	r.mu.Lock()
+	r.misses++
+	r.mu.Unlock()
+	r.Refresh()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if filename, ok = r.paths[inode]; ok {
+		return filename, nil
+	}
+	return "", errors.Errorf("network namespace file with inode %d not found", inode)
+}
+
+// Open - returns an open network namespace file with the inode. The file pins the network namespace, so it stays
+//        valid even if the file it was opened from goes away. The caller is responsible for closing it.
+func (r *Resolver) Open(inode uint64) (*os.File, error) {
+	filename, err := r.Resolve(inode)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filepath.Clean(filename))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't open network namespace file %s", filename)
+	}
+	// The file could be replaced between Resolve and Open
+	stat := &syscall.Stat_t{}
+	if err := syscall.Fstat(int(file.Fd()), stat); err != nil || stat.Ino != inode {
+		_ = file.Close()
+		return nil, errors.Errorf("network namespace file %s doesn't have inode %d anymore", filename, inode)
+	}
+	return file, nil
+}
+
+// Refresh - rescans /proc and the network namespace directories
+func (r *Resolver) Refresh() {
+	paths := r.scan()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paths = paths
+}
+
+// Watch - refreshes the cache every interval until ctx is done
+func (r *Resolver) Watch(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.Refresh()
+			}
+		}
+	}()
+}
+
+// Metrics - returns the number of lookups and of cache misses
+func (r *Resolver) Metrics() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return map[string]string{
+		lookupsKey: fmt.Sprint(r.lookups),
+		missesKey:  fmt.Sprint(r.misses),
+	}
+}
+
+// scan - maps network namespace inodes to files. The lowest pid in a network namespace is preferred: it is the
+//        first process started in it, for a pod it is the pause process living as long as the pod.
+func (r *Resolver) scan() map[uint64]string {
+	paths := map[uint64]string{}
+	pids := map[uint64]uint64{}
+	if files, err := ioutil.ReadDir(procDir); err == nil {
+		for _, f := range files {
+			pid, err := strconv.ParseUint(f.Name(), 10, 64)
+			if err != nil {
+				continue
+			}
+			filename := filepath.Join(procDir, f.Name(), "ns", "net")
+			inode, err := getInode(filename)
+			if err != nil {
+				continue
+			}
+			if lowest, ok := pids[inode]; !ok || pid < lowest {
+				paths[inode] = filename
+				pids[inode] = pid
+			}
+		}
+	}
+	for _, dir := range r.netNSDirs {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			filename := filepath.Join(dir, f.Name())
+			if inode, err := getInode(filename); err == nil {
+				paths[inode] = filename
+			}
+		}
+	}
+	return paths
+}
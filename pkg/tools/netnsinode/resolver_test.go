@@ -0,0 +1,73 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netnsinode_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+)
+
+func TestResolverCachesLookups(t *testing.T) {
+	inode, err := netnsinode.GetMyNetNSInodeNum()
+	require.NoError(t, err)
+
+	resolver := netnsinode.NewResolver(netnsinode.WithNetNSDirs())
+	filename, err := resolver.Resolve(inode)
+	require.NoError(t, err)
+	assert.Regexp(t, "^/proc/[0-9]+/ns/net$", filename)
+	_, err = resolver.Resolve(inode)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"lookups": "2", "misses": "1"}, resolver.Metrics())
+
+	_, err = resolver.Resolve(0)
+	require.Error(t, err)
+	assert.Equal(t, map[string]string{"lookups": "3", "misses": "2"}, resolver.Metrics())
+}
+
+func TestResolverPrefersNetNSDirs(t *testing.T) {
+	inode, err := netnsinode.GetMyNetNSInodeNum()
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "netns")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	// A symlink stands in for a bind mount, both stat to the network namespace inode
+	netnsFilename := filepath.Join(dir, "blue")
+	require.NoError(t, os.Symlink("/proc/self/ns/net", netnsFilename))
+
+	resolver := netnsinode.NewResolver(netnsinode.WithNetNSDirs(dir))
+	filename, err := resolver.Resolve(inode)
+	require.NoError(t, err)
+	assert.Equal(t, netnsFilename, filename)
+
+	file, err := resolver.Open(inode)
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+	stat := &syscall.Stat_t{}
+	require.NoError(t, syscall.Fstat(int(file.Fd()), stat))
+	assert.Equal(t, inode, stat.Ino)
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+// Package netnsroutes provides a way to list the routes already present in a network namespace
+package netnsroutes
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsexec"
+)
+
+const (
+	ipv4RoutesFilename = "net/route"
+	ipv6RoutesFilename = "net/ipv6_route"
+	loopbackInterface  = "lo"
+	// rtfLocal - RTF_LOCAL flag of the routes to the addresses of the namespace itself
+	rtfLocal = 0x80000000
+)
+
+// Route - route found in a network namespace
+type Route struct {
+	Dst       *net.IPNet
+	Interface string
+}
+
+// String - returns the route in the "ip route" like form
+func (r *Route) String() string {
+	return r.Dst.String() + " dev " + r.Interface
+}
+
+// Func - lists the routes of the network namespace referenced by netnsFilename
+type Func func(netnsFilename string) ([]*Route, error)
+
+// List - returns the unicast routes of the network namespace referenced by netnsFilename, default routes and
+//        the routes via the loopback interface are omitted as they can't be shadowed by a more specific route
+func List(netnsFilename string) (routes []*Route, err error) {
+	err = netnsexec.Do(netnsFilename, func() error {
+		procDir := netnsexec.ThreadProcDir()
+		if routes, err = readRoutes(filepath.Join(procDir, ipv4RoutesFilename), ParseIPv4); err != nil {
+			return err
+		}
+		ipv6Routes, ipv6Err := readRoutes(filepath.Join(procDir, ipv6RoutesFilename), ParseIPv6)
+		if ipv6Err != nil && !os.IsNotExist(errors.Cause(ipv6Err)) {
+			return ipv6Err
+		}
+		routes = append(routes, ipv6Routes...)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't list routes of netns %s", netnsFilename)
+	}
+	return routes, nil
+}
+
+func readRoutes(filename string, parse func(r io.Reader) ([]*Route, error)) ([]*Route, error) {
+	/* #nosec */
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't open %s", filename)
+	}
+	defer func() { _ = file.Close() }()
+	return parse(file)
+}
+
+// ParseIPv4 - parses the /proc/net/route format
+func ParseIPv4(r io.Reader) ([]*Route, error) {
+	var routes []*Route
+	scanner := bufio.NewScanner(r)
+	// Skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		dst, err := parseIPv4Hex(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		mask, err := parseIPv4Hex(fields[7])
+		if err != nil {
+			return nil, err
+		}
+		routes = appendRoute(routes, fields[0], &net.IPNet{IP: dst, Mask: net.IPMask(mask)})
+	}
+	return routes, errors.Wrap(scanner.Err(), "can't read IPv4 routes")
+}
+
+// ParseIPv6 - parses the /proc/net/ipv6_route format
+func ParseIPv6(r io.Reader) ([]*Route, error) {
+	var routes []*Route
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		dst, err := hex.DecodeString(fields[0])
+		if err != nil || len(dst) != net.IPv6len {
+			return nil, errors.Errorf("invalid IPv6 destination %s", fields[0])
+		}
+		prefixLen, err := strconv.ParseUint(fields[1], 16, 8)
+		if err != nil || prefixLen > 8*net.IPv6len {
+			return nil, errors.Errorf("invalid IPv6 prefix length %s", fields[1])
+		}
+		flags, err := strconv.ParseUint(fields[8], 16, 32)
+		if err != nil {
+			return nil, errors.Errorf("invalid IPv6 route flags %s", fields[8])
+		}
+		if flags&rtfLocal != 0 {
+			continue
+		}
+		ip := net.IP(dst)
+		if ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+			continue
+		}
+		routes = appendRoute(routes, fields[9], &net.IPNet{IP: ip, Mask: net.CIDRMask(int(prefixLen), 8*net.IPv6len)})
+	}
+	return routes, errors.Wrap(scanner.Err(), "can't read IPv6 routes")
+}
+
+func appendRoute(routes []*Route, iface string, dst *net.IPNet) []*Route {
+	if ones, _ := dst.Mask.Size(); ones == 0 || iface == loopbackInterface {
+		return routes
+	}
+	return append(routes, &Route{Dst: dst, Interface: iface})
+}
+
+// parseIPv4Hex - parses an IPv4 address printed by the kernel as a hex number in the host byte order
+func parseIPv4Hex(s string) (net.IP, error) {
+	value, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, errors.Errorf("invalid IPv4 route field %s", s)
+	}
+	ip := make(net.IP, net.IPv4len)
+	binary.LittleEndian.PutUint32(ip, uint32(value))
+	return ip, nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netnsroutes_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsroutes"
+)
+
+const (
+	ipv4Routes = `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	0101F40A	0003	0	0	0	00000000	0	0	0
+eth0	0001F40A	00000000	0001	0	0	0	00FFFFFF	0	0	0
+eth0	0000600A	0101F40A	0003	0	0	0	0000F0FF	0	0	0
+`
+	ipv6Routes = `fd000000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00000001     eth0
+fe800000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000002 00000000 00000001     eth0
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 fd000000000000000000000000000001 00000400 00000001 00000000 00000003     eth0
+00000000000000000000000000000001 80 00000000000000000000000000000000 00 00000000000000000000000000000000 00000000 00000002 00000000 80200001       lo
+fd000000000000000000000000000002 80 00000000000000000000000000000000 00 00000000000000000000000000000000 00000000 00000002 00000000 80200001     eth0
+ff000000000000000000000000000000 08 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000003 00000000 00000001     eth0
+`
+)
+
+func routeStrings(routes []*netnsroutes.Route) []string {
+	var rv []string
+	for _, route := range routes {
+		rv = append(rv, route.String())
+	}
+	return rv
+}
+
+func TestParseIPv4(t *testing.T) {
+	routes, err := netnsroutes.ParseIPv4(strings.NewReader(ipv4Routes))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"10.244.1.0/24 dev eth0",
+		"10.96.0.0/12 dev eth0",
+	}, routeStrings(routes))
+}
+
+func TestParseIPv6(t *testing.T) {
+	routes, err := netnsroutes.ParseIPv6(strings.NewReader(ipv6Routes))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"fd00::/64 dev eth0",
+	}, routeStrings(routes))
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := netnsroutes.ParseIPv4(strings.NewReader("header\neth0 zz 0 0 0 0 0 00FFFFFF 0 0 0\n"))
+	assert.Error(t, err)
+	_, err = netnsroutes.ParseIPv6(strings.NewReader("fd00 40 0 00 0 0 0 0 0 eth0\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+// Package netnsurl resolves the NetNSURL of the kernel mechanism into a vpp-agent network namespace reference
+package netnsurl
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+)
+
+const (
+	// FileScheme - file:///proc/12/ns/net - path of a network namespace file
+	FileScheme = "file"
+	// InodeScheme - inode://4026531993 - inode number of the network namespace
+	InodeScheme = "inode"
+	// NameScheme - netns://blue - named network namespace from /var/run/netns
+	NameScheme = "netns"
+	// PIDScheme - pid://12 - network namespace of the process
+	PIDScheme = "pid"
+	// ContainerScheme - container://<id> - network namespace of the container, see WithContainerResolver
+	ContainerScheme = "container"
+
+	namedNetNSDir = "/var/run/netns"
+	// pinnedFilename - path of an open file of this process, other processes of the same pid namespace (vpp-agent)
+	//                  are able to open it as well
+	pinnedFilename = "/proc/%d/fd/%d"
+)
+
+// NetNS - resolved network namespace
+type NetNS struct {
+	// Namespace - reference to the network namespace for vpp-agent
+	Namespace *linuxnamespace.NetNamespace
+	// Filename - file of the network namespace, can be used to enter it
+	Filename string
+}
+
+// ContainerResolver - returns the network namespace file of the container with the id
+type ContainerResolver func(id string) (string, error)
+
+// Resolver - resolves NetNSURLs
+type Resolver struct {
+	resolveContainer ContainerResolver
+	resolveInode     func(inode string) (string, error)
+	openInode        func(inode string) (*os.File, error)
+	pins             map[string]*pin
+	mu               sync.Mutex
+}
+
+// pin - open network namespace file of a connection
+type pin struct {
+	netNSURL string
+	file     *os.File
+	netNS    *NetNS
+}
+
+// Option - option for NewResolver
+type Option func(r *Resolver)
+
+// WithContainerResolver - enables ContainerScheme, by default container references are rejected
+func WithContainerResolver(resolveContainer ContainerResolver) Option {
+	return func(r *Resolver) {
+		r.resolveContainer = resolveContainer
+	}
+}
+
+// WithInodeResolver - resolves InodeScheme references with inodeResolver instead of the one shared by the process
+func WithInodeResolver(inodeResolver *netnsinode.Resolver) Option {
+	return func(r *Resolver) {
+		r.resolveInode = func(inode string) (string, error) {
+			inodeNum, err := strconv.ParseUint(inode, 10, 64)
+			if err != nil {
+				return "", errors.Errorf("inode must be an unsigned int, instead was: %q", inode)
+			}
+			return inodeResolver.Resolve(inodeNum)
+		}
+		r.openInode = func(inode string) (*os.File, error) {
+			inodeNum, err := strconv.ParseUint(inode, 10, 64)
+			if err != nil {
+				return nil, errors.Errorf("inode must be an unsigned int, instead was: %q", inode)
+			}
+			return inodeResolver.Open(inodeNum)
+		}
+	}
+}
+
+// NewResolver - creates a new Resolver
+func NewResolver(options ...Option) *Resolver {
+	r := &Resolver{
+		resolveInode: netnsinode.LinuxNetNSFileName,
+		openInode:    netnsinode.OpenLinuxNetNSFile,
+		pins:         map[string]*pin{},
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// Resolve - resolves the netNSURL to the network namespace it references
+func (r *Resolver) Resolve(netNSURL string) (*NetNS, error) {
+	u, err := url.Parse(netNSURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid NetNSURL %q", netNSURL)
+	}
+	// Both scheme://ref and scheme:ref are accepted
+	ref := u.Host
+	if ref == "" {
+		ref = u.Opaque
+	}
+	switch u.Scheme {
+	case FileScheme:
+		return fdNetNS(u.Path), nil
+	case InodeScheme:
+		filename, err := r.resolveInode(ref)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't resolve NetNSURL %q", netNSURL)
+		}
+		return fdNetNS(filename), nil
+	case NameScheme:
+		if !isValidName(ref) || u.Path != "" {
+			return nil, errors.Errorf("invalid network namespace name in NetNSURL %q", netNSURL)
+		}
+		return &NetNS{
+			Namespace: &linuxnamespace.NetNamespace{
+				Type:      linuxnamespace.NetNamespace_NSID,
+				Reference: ref,
+			},
+			Filename: filepath.Join(namedNetNSDir, ref),
+		}, nil
+	case PIDScheme:
+		if _, err := strconv.ParseUint(ref, 10, 32); err != nil {
+			return nil, errors.Errorf("invalid pid in NetNSURL %q", netNSURL)
+		}
+		return &NetNS{
+			Namespace: &linuxnamespace.NetNamespace{
+				Type:      linuxnamespace.NetNamespace_PID,
+				Reference: ref,
+			},
+			Filename: filepath.Join("/proc", ref, "ns", "net"),
+		}, nil
+	case ContainerScheme:
+		if r.resolveContainer == nil {
+			return nil, errors.Errorf("no container resolver is configured for NetNSURL %q", netNSURL)
+		}
+		filename, err := r.resolveContainer(ref)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't resolve NetNSURL %q", netNSURL)
+		}
+		return fdNetNS(filename), nil
+	}
+	return nil, errors.Errorf("unsupported NetNSURL scheme %q: %q", u.Scheme, netNSURL)
+}
+
+// ResolveFor - resolves the netNSURL used by the connection id like Resolve, except that InodeScheme references are
+//              pinned: the network namespace file is kept open until Release(id) and referenced by its
+//              /proc/<pid>/fd/<fd> path, so the reference stays valid even if the process the network namespace was
+//              found through exits
+func (r *Resolver) ResolveFor(id, netNSURL string) (*NetNS, error) {
+	u, err := url.Parse(netNSURL)
+	if err != nil || u.Scheme != InodeScheme {
+		return r.Resolve(netNSURL)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.pins[id]; ok {
+		if p.netNSURL == netNSURL {
+			return p.netNS, nil
+		}
+		_ = p.file.Close()
+		delete(r.pins, id)
+	}
+	ref := u.Host
+	if ref == "" {
+		ref = u.Opaque
+	}
+	file, err := r.openInode(ref)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't resolve NetNSURL %q", netNSURL)
+	}
+	p := &pin{
+		netNSURL: netNSURL,
+		file:     file,
+		netNS:    fdNetNS(fmt.Sprintf(pinnedFilename, os.Getpid(), file.Fd())),
+	}
+	r.pins[id] = p
+	return p.netNS, nil
+}
+
+// Release - closes the network namespace file pinned by ResolveFor for the connection id
+func (r *Resolver) Release(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.pins[id]; ok {
+		_ = p.file.Close()
+		delete(r.pins, id)
+	}
+}
+
+// isValidName - returns true if name is a file name in /var/run/netns
+func isValidName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name
+}
+
+func fdNetNS(filename string) *NetNS {
+	return &NetNS{
+		Namespace: &linuxnamespace.NetNamespace{
+			Type:      linuxnamespace.NetNamespace_FD,
+			Reference: filename,
+		},
+		Filename: filename,
+	}
+}
+
+// Filename - returns the file of the vpp-agent network namespace reference, empty for the current network namespace
+func Filename(netNS *linuxnamespace.NetNamespace) string {
+	switch netNS.GetType() {
+	case linuxnamespace.NetNamespace_FD:
+		return netNS.GetReference()
+	case linuxnamespace.NetNamespace_NSID:
+		return filepath.Join(namedNetNSDir, netNS.GetReference())
+	case linuxnamespace.NetNamespace_PID:
+		return filepath.Join("/proc", netNS.GetReference(), "ns", "net")
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netnsurl_test
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
+)
+
+func TestResolve(t *testing.T) {
+	resolver := netnsurl.NewResolver(netnsurl.WithContainerResolver(func(id string) (string, error) {
+		if id == "c0ffee" {
+			return "/proc/42/ns/net", nil
+		}
+		return "", errors.Errorf("container %s not found", id)
+	}))
+	for _, testCase := range []struct {
+		netNSURL      string
+		nsType        linuxnamespace.NetNamespace_ReferenceType
+		reference     string
+		netnsFilename string
+	}{
+		{"file:///proc/12/ns/net", linuxnamespace.NetNamespace_FD, "/proc/12/ns/net", "/proc/12/ns/net"},
+		{"netns://blue", linuxnamespace.NetNamespace_NSID, "blue", "/var/run/netns/blue"},
+		{"pid://12", linuxnamespace.NetNamespace_PID, "12", "/proc/12/ns/net"},
+		{"pid:12", linuxnamespace.NetNamespace_PID, "12", "/proc/12/ns/net"},
+		{"container://c0ffee", linuxnamespace.NetNamespace_FD, "/proc/42/ns/net", "/proc/42/ns/net"},
+	} {
+		netNS, err := resolver.Resolve(testCase.netNSURL)
+		require.NoError(t, err, testCase.netNSURL)
+		assert.Equal(t, testCase.nsType, netNS.Namespace.GetType(), testCase.netNSURL)
+		assert.Equal(t, testCase.reference, netNS.Namespace.GetReference(), testCase.netNSURL)
+		assert.Equal(t, testCase.netnsFilename, netNS.Filename, testCase.netNSURL)
+	}
+}
+
+func TestResolveInode(t *testing.T) {
+	inode, err := netnsinode.GetMyNetNSInodeNum()
+	require.NoError(t, err)
+	netNS, err := netnsurl.NewResolver().Resolve("inode://" + strconv.FormatUint(inode, 10))
+	require.NoError(t, err)
+	assert.Equal(t, linuxnamespace.NetNamespace_FD, netNS.Namespace.GetType())
+	assert.Regexp(t, "^/proc/[0-9]+/ns/net$", netNS.Filename)
+}
+
+func TestResolveForPinsInode(t *testing.T) {
+	inode, err := netnsinode.GetMyNetNSInodeNum()
+	require.NoError(t, err)
+	netNSURL := "inode://" + strconv.FormatUint(inode, 10)
+	resolver := netnsurl.NewResolver()
+
+	netNS, err := resolver.ResolveFor("conn-1", netNSURL)
+	require.NoError(t, err)
+	assert.Equal(t, linuxnamespace.NetNamespace_FD, netNS.Namespace.GetType())
+	assert.Regexp(t, "^/proc/[0-9]+/fd/[0-9]+$", netNS.Filename)
+	info, err := os.Stat(netNS.Filename)
+	require.NoError(t, err)
+	assert.Equal(t, inode, info.Sys().(*syscall.Stat_t).Ino)
+
+	// Refresh reuses the pinned file
+	refreshed, err := resolver.ResolveFor("conn-1", netNSURL)
+	require.NoError(t, err)
+	assert.Equal(t, netNS.Filename, refreshed.Filename)
+
+	resolver.Release("conn-1")
+	_, err = os.Stat(netNS.Filename)
+	assert.Error(t, err)
+
+	// Other schemes are not pinned
+	netNS, err = resolver.ResolveFor("conn-2", "pid://12")
+	require.NoError(t, err)
+	assert.Equal(t, "/proc/12/ns/net", netNS.Filename)
+}
+
+func TestResolveErrors(t *testing.T) {
+	resolver := netnsurl.NewResolver()
+	for _, netNSURL := range []string{
+		"tcp://127.0.0.1:5000",
+		"netns://../blue",
+		"pid://self",
+		"container://c0ffee",
+		"inode://not-a-number",
+	} {
+		_, err := resolver.Resolve(netNSURL)
+		assert.Error(t, err, netNSURL)
+	}
+}